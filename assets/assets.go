@@ -1,14 +1,52 @@
 package assets
 
 import (
+	"bytes"
+	"embed"
 	"fmt"
 	"image"
 	_ "image/png"
+	"io/fs"
 	"os"
+	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
 )
 
+//go:embed assets/*.png assets/*.json
+var embedded embed.FS
+
+// AssetRootEnv overrides the embedded assets with a directory on disk, which enables hot-reload
+const AssetRootEnv = "PHYSENGINE_ASSETS"
+
+// Default is the manager used by Image and the factories. It can be replaced
+// before the scene is configured to load from another root.
+var Default = newDefaultManager()
+
+func newDefaultManager() *Manager {
+	if root := os.Getenv(AssetRootEnv); root != "" {
+		m := NewManager(root)
+		m.SetHotReload(true, time.Second)
+		return m
+	}
+	sub, err := fs.Sub(embedded, "assets")
+	if err != nil {
+		panic(err)
+	}
+	return NewFSManager(sub)
+}
+
+// Image returns a cached image from the default manager, or a placeholder if it cannot be loaded
+func Image(key string) *ebiten.Image {
+	return Default.Image(key)
+}
+
+// ReadFile returns the raw contents of an asset from the default manager
+func ReadFile(key string) ([]byte, error) {
+	return Default.ReadFile(key)
+}
+
+// GetImage loads an image directly from a file path without caching
 func GetImage(path string) (*ebiten.Image, error) {
 	file, err := os.Open(path)
 	if err != nil {
@@ -24,3 +62,17 @@ func GetImage(path string) (*ebiten.Image, error) {
 	fmt.Println("Loaded image:", path)
 	return ebiten.NewImageFromImage(img), nil
 }
+
+// ImageData decodes an image asset from the default manager without uploading it to the GPU,
+// so its pixels can be read before the game loop starts
+func ImageData(key string) (image.Image, error) {
+	data, err := ReadFile(key)
+	if err != nil {
+		return nil, err
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image %s: %w", key, err)
+	}
+	return img, nil
+}
@@ -0,0 +1,185 @@
+package assets
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"io/fs"
+	"os"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+const placeholderSize = 64
+
+// cachedImage is a decoded image and the state used to detect file changes
+type cachedImage struct {
+	image   *ebiten.Image
+	modTime time.Time
+	missing bool
+}
+
+// Manager resolves asset keys relative to a file system, caches decoded images
+// and substitutes a placeholder texture when loading fails
+type Manager struct {
+	fsys  fs.FS
+	cache map[string]*cachedImage
+	mu    sync.Mutex
+
+	hotReload    bool
+	pollInterval time.Duration
+	lastPoll     time.Time
+
+	placeholder *ebiten.Image
+}
+
+// NewManager creates a manager that loads assets from a directory on disk
+func NewManager(root string) *Manager {
+	return NewFSManager(os.DirFS(root))
+}
+
+// NewFSManager creates a manager that loads assets from any file system, e.g. an embed.FS
+func NewFSManager(fsys fs.FS) *Manager {
+	return &Manager{
+		fsys:         fsys,
+		cache:        map[string]*cachedImage{},
+		pollInterval: time.Second,
+	}
+}
+
+// SetHotReload enables or disables reloading of changed files, checked at most once per interval
+func (m *Manager) SetHotReload(enabled bool, interval time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.hotReload = enabled
+	if interval > 0 {
+		m.pollInterval = interval
+	}
+}
+
+// Image returns the image for a key, loading and caching it on first use.
+// Failed loads return a shared placeholder texture.
+func (m *Manager) Image(key string) *ebiten.Image {
+	img, err := m.LoadImage(key)
+	if err != nil {
+		fmt.Println("assets:", err)
+	}
+	return img
+}
+
+// LoadImage returns the image for a key and the load error, if any.
+// The returned image is never nil: failed loads yield the placeholder.
+func (m *Manager) LoadImage(key string) (*ebiten.Image, error) {
+	key = path.Clean(key)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if cached, ok := m.cache[key]; ok {
+		return cached.image, nil
+	}
+
+	decoded, modTime, err := m.decode(key)
+	if err != nil {
+		m.cache[key] = &cachedImage{image: m.placeholderImage(), missing: true}
+		return m.cache[key].image, err
+	}
+	m.cache[key] = &cachedImage{image: ebiten.NewImageFromImage(decoded), modTime: modTime}
+	return m.cache[key].image, nil
+}
+
+// Forget drops a key from the cache so the next request reloads it
+func (m *Manager) Forget(key string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.cache, path.Clean(key))
+}
+
+// Poll reloads cached images whose files changed since they were loaded.
+// Images of unchanged size are updated in place, so existing references see the new pixels.
+func (m *Manager) Poll() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.hotReload || time.Since(m.lastPoll) < m.pollInterval {
+		return
+	}
+	m.lastPoll = time.Now()
+
+	for key, cached := range m.cache {
+		info, err := fs.Stat(m.fsys, key)
+		if err != nil || (!cached.missing && !info.ModTime().After(cached.modTime)) {
+			continue
+		}
+		decoded, modTime, err := m.decode(key)
+		if err != nil {
+			fmt.Println("assets: reload failed:", err)
+			continue
+		}
+
+		rgba := image.NewRGBA(image.Rect(0, 0, decoded.Bounds().Dx(), decoded.Bounds().Dy()))
+		draw.Draw(rgba, rgba.Bounds(), decoded, decoded.Bounds().Min, draw.Src)
+		if !cached.missing && cached.image.Bounds().Size() == rgba.Bounds().Size() {
+			cached.image.WritePixels(rgba.Pix)
+		} else {
+			// Size changed or the asset was missing: only new lookups see the image
+			cached.image = ebiten.NewImageFromImage(rgba)
+		}
+		cached.modTime = modTime
+		cached.missing = false
+		fmt.Println("assets: reloaded", key)
+	}
+}
+
+func (m *Manager) decode(key string) (image.Image, time.Time, error) {
+	file, err := m.fsys.Open(key)
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("failed to open image file %s: %w", key, err)
+	}
+	defer file.Close()
+
+	var modTime time.Time
+	if info, err := file.Stat(); err == nil {
+		modTime = info.ModTime()
+	}
+
+	img, _, err := image.Decode(file)
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("failed to decode image %s: %w", key, err)
+	}
+	return img, modTime, nil
+}
+
+// placeholderImage returns a magenta and black checkerboard, generated on first use
+func (m *Manager) placeholderImage() *ebiten.Image {
+	if m.placeholder != nil {
+		return m.placeholder
+	}
+	img := image.NewRGBA(image.Rect(0, 0, placeholderSize, placeholderSize))
+	magenta := color.RGBA{R: 255, G: 0, B: 255, A: 255}
+	black := color.RGBA{A: 255}
+	for y := 0; y < placeholderSize; y++ {
+		for x := 0; x < placeholderSize; x++ {
+			if (x/8+y/8)%2 == 0 {
+				img.SetRGBA(x, y, magenta)
+			} else {
+				img.SetRGBA(x, y, black)
+			}
+		}
+	}
+	m.placeholder = ebiten.NewImageFromImage(img)
+	return m.placeholder
+}
+
+// ReadFile returns the raw contents of a non-image asset such as a prefab file
+func (m *Manager) ReadFile(key string) ([]byte, error) {
+	data, err := fs.ReadFile(m.fsys, path.Clean(key))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read asset %s: %w", key, err)
+	}
+	return data, nil
+}
@@ -7,46 +7,31 @@ import (
 )
 
 type AABB_Data struct {
-	Min Vec2.Vec2
+	Min Vec2.Vec2 // Corners relative to the body position, so the box can be offset
 	Max Vec2.Vec2
+	Rot float64 // Local rotation of the box around its own centre
 }
 
 var AABB_Component = donburi.NewComponentType[AABB_Data]()
 
+// Offset returns the centre of the box relative to the body position
+func (a *AABB_Data) Offset() Vec2.Vec2 {
+	return Vec2.Vec2{X: (a.Min.X + a.Max.X) / 2, Y: (a.Min.Y + a.Max.Y) / 2}
+}
+
+// HalfExtents returns half the width and height of the box
+func (a *AABB_Data) HalfExtents() Vec2.Vec2 {
+	return Vec2.Vec2{X: (a.Max.X - a.Min.X) / 2, Y: (a.Max.Y - a.Min.Y) / 2}
+}
+
+// Shape returns the box as a body-space collision shape
+func (a *AABB_Data) Shape() Shape {
+	return Shape{Kind: ShapeBox, Offset: a.Offset(), Rot: a.Rot, HalfExtents: a.HalfExtents()}
+}
+
+// AABBvsAABB reports whether the boxes of two bodies overlap, honouring their offsets and
+// rotations
 func AABBvsAABB(a1, a2 *donburi.Entry) bool {
-	if !a1.HasComponent(Transform) || !a2.HasComponent(Transform) {
-		return false
-	}
-	if !a1.HasComponent(AABB_Component) || !a2.HasComponent(AABB_Component) {
-		return false
-	}
-
-	tr1 := Transform.Get(a1)
-	tr2 := Transform.Get(a2)
-	AABB1 := donburi.Get[AABB_Data](a1, AABB_Component)
-	AABB2 := donburi.Get[AABB_Data](a2, AABB_Component)
-
-	if tr1 == nil || tr2 == nil || AABB1 == nil || AABB2 == nil {
-		return false
-	}
-
-	// Calculate world positions of AABB bounds
-	a1_min_x := tr1.Pos.X + AABB1.Min.X
-	a1_max_x := tr1.Pos.X + AABB1.Max.X
-	a1_min_y := tr1.Pos.Y + AABB1.Min.Y
-	a1_max_y := tr1.Pos.Y + AABB1.Max.Y
-
-	a2_min_x := tr2.Pos.X + AABB2.Min.X
-	a2_max_x := tr2.Pos.X + AABB2.Max.X
-	a2_min_y := tr2.Pos.Y + AABB2.Min.Y
-	a2_max_y := tr2.Pos.Y + AABB2.Max.Y
-
-	// Check for overlap on both axes
-	if (a1_max_x < a2_min_x) || (a1_min_x > a2_max_x) {
-		return false
-	}
-	if (a1_max_y < a2_min_y) || (a1_min_y > a2_max_y) {
-		return false
-	}
-	return true
+	ok, _, _ := RotatedAABBvsAABB(a1, a2)
+	return ok
 }
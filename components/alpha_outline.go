@@ -0,0 +1,88 @@
+package components
+
+import (
+	"fmt"
+	"image"
+	Vec2 "physengine/helpers/vec2"
+)
+
+// TraceAlphaOutline traces the outline of the largest opaque region of an image, e.g. a
+// sprite loaded from assets. Pixels with alpha above threshold (0-255) are solid, and the
+// pixel staircase is simplified with Douglas–Peucker to within tolerance pixels. Points are
+// in pixels relative to the image centre with Y up, wound clockwise; holes are ignored.
+func TraceAlphaOutline(img image.Image, threshold uint8, tolerance float64) ([]Vec2.Vec2, error) {
+	bounds := img.Bounds()
+	tiles := make([][]bool, bounds.Dy())
+	for y := range tiles {
+		tiles[y] = make([]bool, bounds.Dx())
+		for x := range tiles[y] {
+			_, _, _, a := img.At(bounds.Min.X+x, bounds.Min.Y+y).RGBA()
+			tiles[y][x] = a>>8 > uint32(threshold)
+		}
+	}
+
+	// Outer outlines wind clockwise, so the largest region has the most negative area
+	var outline []Vec2.Vec2
+	largest := 0.0
+	for _, loop := range TraceTileOutlines(tiles, 1) {
+		if area := -signedArea(loop); area > largest {
+			largest = area
+			outline = loop
+		}
+	}
+	if outline == nil {
+		return nil, fmt.Errorf("image has no pixels with alpha above %d", threshold)
+	}
+
+	half := Vec2.Vec2{X: float64(bounds.Dx()) / 2, Y: -float64(bounds.Dy()) / 2}
+	for i := range outline {
+		outline[i] = outline[i].Add(half.Mult(-1))
+	}
+	outline = SimplifyPolygon(outline, tolerance)
+	if len(outline) < 3 {
+		return nil, fmt.Errorf("outline collapsed below 3 points, lower the tolerance")
+	}
+	return outline, nil
+}
+
+// SimplifyPolygon reduces a closed polygon with Douglas–Peucker, keeping every point that is
+// further than tolerance from the simplified outline
+func SimplifyPolygon(points []Vec2.Vec2, tolerance float64) []Vec2.Vec2 {
+	if len(points) < 4 || tolerance <= 0 {
+		return points
+	}
+	// Split the loop at the point furthest from the first one and simplify both halves
+	far := 0
+	for i, p := range points {
+		if Vec2.Distance(p, points[0]) > Vec2.Distance(points[far], points[0]) {
+			far = i
+		}
+	}
+	closed := append(append([]Vec2.Vec2(nil), points...), points[0])
+	first := simplifyPolyline(closed[:far+1], tolerance)
+	second := simplifyPolyline(closed[far:], tolerance)
+	simplified := make([]Vec2.Vec2, 0, len(first)+len(second)-2)
+	simplified = append(simplified, first[:len(first)-1]...)
+	return append(simplified, second[:len(second)-1]...)
+}
+
+// simplifyPolyline is Douglas–Peucker on an open polyline, keeping both ends
+func simplifyPolyline(points []Vec2.Vec2, tolerance float64) []Vec2.Vec2 {
+	if len(points) < 3 {
+		return points
+	}
+	first, last := points[0], points[len(points)-1]
+	worst, worstDist := 0, 0.0
+	for i := 1; i < len(points)-1; i++ {
+		d := Vec2.Distance(points[i], ClosestPointOnSegment(points[i], first, last))
+		if d > worstDist {
+			worst, worstDist = i, d
+		}
+	}
+	if worstDist <= tolerance {
+		return []Vec2.Vec2{first, last}
+	}
+	left := simplifyPolyline(points[:worst+1], tolerance)
+	right := simplifyPolyline(points[worst:], tolerance)
+	return append(left[:len(left)-1:len(left)-1], right...)
+}
@@ -0,0 +1,13 @@
+package components
+
+import "github.com/yohamta/donburi"
+
+// AttractorData makes a body pull every other dynamic body with Newtonian gravity,
+// a = G·M·r / (|r|² + ε²)^(3/2), where G is the world's GravitationalConstant and ε the
+// softening length. Dynamic attractors also pull on each other, giving an n-body system.
+type AttractorData struct {
+	Mass      float64 // Pulling mass, 0 to use the body's own mass
+	Softening float64 // ε, which keeps the pull finite when bodies pass close to each other
+}
+
+var Attractor = donburi.NewComponentType[AttractorData]()
@@ -0,0 +1,39 @@
+package components
+
+import (
+	"math"
+	Vec2 "physengine/helpers/vec2"
+
+	"github.com/yohamta/donburi"
+)
+
+// WorldBounds returns the world-space axis-aligned bounding box of all of an entity's colliders
+func WorldBounds(entry *donburi.Entry) (Vec2.Vec2, Vec2.Vec2, bool) {
+	shapes := WorldShapes(entry)
+	if len(shapes) == 0 {
+		return Vec2.Vec2{}, Vec2.Vec2{}, false
+	}
+	min, max := shapes[0].Bounds()
+	for _, s := range shapes[1:] {
+		smin, smax := s.Bounds()
+		min = Vec2.Vec2{X: math.Min(min.X, smin.X), Y: math.Min(min.Y, smin.Y)}
+		max = Vec2.Vec2{X: math.Max(max.X, smax.X), Y: math.Max(max.Y, smax.Y)}
+	}
+	return min, max, true
+}
+
+// pointsBounds returns the axis-aligned bounds of a point set
+func pointsBounds(points []Vec2.Vec2) (Vec2.Vec2, Vec2.Vec2, bool) {
+	if len(points) == 0 {
+		return Vec2.Vec2{}, Vec2.Vec2{}, false
+	}
+	min := Vec2.Vec2{X: math.Inf(1), Y: math.Inf(1)}
+	max := Vec2.Vec2{X: math.Inf(-1), Y: math.Inf(-1)}
+	for _, p := range points {
+		min.X = math.Min(min.X, p.X)
+		min.Y = math.Min(min.Y, p.Y)
+		max.X = math.Max(max.X, p.X)
+		max.Y = math.Max(max.Y, p.Y)
+	}
+	return min, max, true
+}
@@ -0,0 +1,181 @@
+package components
+
+import (
+	"image/color"
+	"math"
+	Vec2 "physengine/helpers/vec2"
+	"sort"
+
+	"github.com/yohamta/donburi"
+)
+
+// BuoyancyVolumeData is a region of fluid that floats the bodies inside it. The region is a
+// world-space polygon; concave outlines are split into convex pieces by SetPolygon.
+type BuoyancyVolumeData struct {
+	Density     float64    // Mass per unit area, comparable to MaterialData.Density
+	LinearDrag  float64    // Rate in 1/s at which a fully submerged body of the fluid's density matches the flow
+	AngularDrag float64    // Rate in 1/s at which a fully submerged body of the fluid's density stops spinning
+	Flow        Vec2.Vec2  // Velocity of the fluid, so bodies drift with a current
+	Color       color.RGBA // Premultiplied, as color.RGBA always is
+
+	polygon []Vec2.Vec2
+	pieces  [][]Vec2.Vec2
+}
+
+var BuoyancyVolume = donburi.NewComponentType[BuoyancyVolumeData]()
+
+// SetPolygon sets the outline of the volume in world space
+func (b *BuoyancyVolumeData) SetPolygon(points []Vec2.Vec2) error {
+	pieces, err := DecomposePolygon(points)
+	if err != nil {
+		return err
+	}
+	b.polygon = append([]Vec2.Vec2(nil), points...)
+	b.pieces = pieces
+	return nil
+}
+
+// SetRect makes the volume the box from min to max
+func (b *BuoyancyVolumeData) SetRect(min, max Vec2.Vec2) {
+	b.polygon = []Vec2.Vec2{min, {X: max.X, Y: min.Y}, max, {X: min.X, Y: max.Y}}
+	b.pieces = [][]Vec2.Vec2{b.polygon}
+}
+
+// Polygon returns the outline of the volume
+func (b *BuoyancyVolumeData) Polygon() []Vec2.Vec2 {
+	return b.polygon
+}
+
+// Pieces returns the convex counter-clockwise pieces of the volume
+func (b *BuoyancyVolumeData) Pieces() [][]Vec2.Vec2 {
+	return b.pieces
+}
+
+// Bounds returns the world-space box around the volume
+func (b *BuoyancyVolumeData) Bounds() (Vec2.Vec2, Vec2.Vec2) {
+	min, max, _ := pointsBounds(b.polygon)
+	return min, max
+}
+
+// Submerged returns the area of a world shape inside the volume and the centroid of that
+// area. Shapes without area, such as segments, are never submerged.
+func (b *BuoyancyVolumeData) Submerged(shape WorldShape) (float64, Vec2.Vec2) {
+	outline := ShapeOutline(shape)
+	if len(outline) < 3 {
+		return 0, Vec2.Vec2{}
+	}
+	area := 0.0
+	var moment Vec2.Vec2
+	for _, piece := range b.pieces {
+		inside := ClipConvex(outline, piece)
+		if len(inside) < 3 {
+			continue
+		}
+		a := signedArea(inside)
+		area += a
+		moment.AddUpdate(polygonCentroid(inside).Mult(a))
+	}
+	if area <= 0 {
+		return 0, Vec2.Vec2{}
+	}
+	return area, moment.Mult(1 / area)
+}
+
+// outlineSegments is how many sides approximate a full circle in ShapeOutline
+const outlineSegments = 32
+
+// ShapeOutline returns a counter-clockwise convex polygon covering a world shape. Rounded
+// shapes are approximated with straight sides; a circle keeps its exact area.
+func ShapeOutline(shape WorldShape) []Vec2.Vec2 {
+	if shape.Radius == 0 {
+		if len(shape.Vertices) < 3 {
+			return nil
+		}
+		outline := append([]Vec2.Vec2(nil), shape.Vertices...)
+		if signedArea(outline) < 0 {
+			outline = reversedPoints(outline)
+		}
+		return outline
+	}
+
+	radius := shape.Radius
+	if len(shape.Vertices) == 1 {
+		// Grow the polygon until its area matches the circle
+		step := 2 * math.Pi / outlineSegments
+		radius *= math.Sqrt(2 * math.Pi / (outlineSegments * math.Sin(step)))
+	}
+	points := make([]Vec2.Vec2, 0, len(shape.Vertices)*outlineSegments)
+	for _, v := range shape.Vertices {
+		for i := 0; i < outlineSegments; i++ {
+			angle := 2 * math.Pi * float64(i) / outlineSegments
+			points = append(points, v.Add(Vec2.Vec2{X: math.Cos(angle) * radius, Y: math.Sin(angle) * radius}))
+		}
+	}
+	if len(shape.Vertices) == 1 {
+		return points
+	}
+	return convexHull(points)
+}
+
+// convexHull returns the counter-clockwise convex hull of a set of points
+func convexHull(points []Vec2.Vec2) []Vec2.Vec2 {
+	sorted := append([]Vec2.Vec2(nil), points...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].X != sorted[j].X {
+			return sorted[i].X < sorted[j].X
+		}
+		return sorted[i].Y < sorted[j].Y
+	})
+	if len(sorted) < 3 {
+		return sorted
+	}
+	turn := func(o, a, b Vec2.Vec2) float64 {
+		return Vec2.CrossProductVecVec(a.Add(o.Mult(-1)), b.Add(o.Mult(-1)))
+	}
+	hull := make([]Vec2.Vec2, 0, 2*len(sorted))
+	// Lower chain, then upper chain
+	for _, p := range sorted {
+		for len(hull) >= 2 && turn(hull[len(hull)-2], hull[len(hull)-1], p) <= 0 {
+			hull = hull[:len(hull)-1]
+		}
+		hull = append(hull, p)
+	}
+	lower := len(hull) + 1
+	for i := len(sorted) - 2; i >= 0; i-- {
+		p := sorted[i]
+		for len(hull) >= lower && turn(hull[len(hull)-2], hull[len(hull)-1], p) <= 0 {
+			hull = hull[:len(hull)-1]
+		}
+		hull = append(hull, p)
+	}
+	return hull[:len(hull)-1]
+}
+
+// ClipConvex returns the part of a polygon inside a convex counter-clockwise polygon
+// (Sutherland–Hodgman clipping)
+func ClipConvex(subject, clip []Vec2.Vec2) []Vec2.Vec2 {
+	output := subject
+	for i := range clip {
+		if len(output) == 0 {
+			break
+		}
+		a, b := clip[i], clip[(i+1)%len(clip)]
+		edge := b.Add(a.Mult(-1))
+		side := func(p Vec2.Vec2) float64 { return Vec2.CrossProductVecVec(edge, p.Add(a.Mult(-1))) }
+
+		input := output
+		output = make([]Vec2.Vec2, 0, len(input)+1)
+		for j := range input {
+			p, q := input[j], input[(j+1)%len(input)]
+			sp, sq := side(p), side(q)
+			if sp >= 0 {
+				output = append(output, p)
+			}
+			if (sp >= 0) != (sq >= 0) {
+				t := sp / (sp - sq)
+				output = append(output, p.Add(q.Add(p.Mult(-1)).Mult(t)))
+			}
+		}
+	}
+	return output
+}
@@ -1,26 +1,154 @@
 package components
 
 import (
+	"image"
 	Vec2 "physengine/helpers/vec2"
+	"sort"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/yohamta/donburi"
 )
 
+// Render layers used by Camera.LayerMask and RenderLayer
+const (
+	LayerDefault uint32 = 1 << iota
+	LayerUI
+	LayerDebug
+
+	LayerAll uint32 = ^uint32(0)
+)
+
+// ViewportRect is the screen rectangle of a camera as fractions (0..1) of the window size
+type ViewportRect struct {
+	X float64
+	Y float64
+	W float64
+	H float64
+}
+
+// FullScreen covers the whole window
+var FullScreen = ViewportRect{X: 0, Y: 0, W: 1, H: 1}
+
 type CameraData struct {
-	ViewportSizeX      float64
+	Viewport           ViewportRect // Normalized screen rectangle
+	Priority           int          // Cameras with higher priority are drawn on top
+	LayerMask          uint32       // Render layers visible to this camera
+	ViewportPosX       float64      // Pixel position of the viewport, updated by ResizeCameras
+	ViewportPosY       float64
+	ViewportSizeX      float64 // Pixel size of the viewport, updated by ResizeCameras
 	ViewportSizeY      float64
 	Zoom               Vec2.Vec2
 	LastMousePos       Vec2.Vec2 // World coordinates for collision detection
 	LastScreenMousePos Vec2.Vec2 // Screen coordinates for delta calculation
 	MouseDelta         Vec2.Vec2
+	Follow             *donburi.Entry // Entity the camera stays centred on, if set
 	Op                 ebiten.DrawImageOptions
 }
 
 var Camera = donburi.NewComponentType[CameraData]()
 
+// RenderLayerData assigns an entity to one or more render layers.
+// Entities without it are on LayerDefault.
+type RenderLayerData struct {
+	Mask uint32
+}
+
+var RenderLayer = donburi.NewComponentType[RenderLayerData]()
+
+// EntityLayerMask returns the render layers of an entity
+func EntityLayerMask(entry *donburi.Entry) uint32 {
+	if entry.HasComponent(RenderLayer) {
+		return RenderLayer.Get(entry).Mask
+	}
+	return LayerDefault
+}
+
+// SeesEntity reports whether the camera renders the given entity
+func (c *CameraData) SeesEntity(entry *donburi.Entry) bool {
+	return c.LayerMask&EntityLayerMask(entry) != 0
+}
+
+// ScreenRect returns the pixel rectangle of the camera viewport
+func (c *CameraData) ScreenRect() image.Rectangle {
+	return image.Rect(
+		int(c.ViewportPosX),
+		int(c.ViewportPosY),
+		int(c.ViewportPosX+c.ViewportSizeX),
+		int(c.ViewportPosY+c.ViewportSizeY),
+	)
+}
+
+// ContainsScreenPoint reports whether a screen point lies inside the viewport
+func (c *CameraData) ContainsScreenPoint(p Vec2.Vec2) bool {
+	return p.X >= c.ViewportPosX && p.X < c.ViewportPosX+c.ViewportSizeX &&
+		p.Y >= c.ViewportPosY && p.Y < c.ViewportPosY+c.ViewportSizeY
+}
+
+// WorldToScreen converts a world position to screen coordinates (Y axis points up in world space)
+func (c *CameraData) WorldToScreen(camPos Vec2.Vec2, p Vec2.Vec2) Vec2.Vec2 {
+	return Vec2.Vec2{
+		X: (p.X-camPos.X)*c.Zoom.X + c.ViewportPosX + c.ViewportSizeX/2,
+		Y: -(p.Y-camPos.Y)*c.Zoom.Y + c.ViewportPosY + c.ViewportSizeY/2,
+	}
+}
+
+// ScreenToWorld converts screen coordinates to a world position
+func (c *CameraData) ScreenToWorld(camPos Vec2.Vec2, p Vec2.Vec2) Vec2.Vec2 {
+	return Vec2.Vec2{
+		X: (p.X-c.ViewportPosX-c.ViewportSizeX/2)/c.Zoom.X + camPos.X,
+		Y: -(p.Y-c.ViewportPosY-c.ViewportSizeY/2)/c.Zoom.Y + camPos.Y,
+	}
+}
+
+// ResizeCameras recomputes the pixel viewport of every camera from the window size
+func ResizeCameras(w donburi.World, width, height int) {
+	Camera.Each(w, func(entry *donburi.Entry) {
+		cam := Camera.Get(entry)
+		cam.ViewportPosX = cam.Viewport.X * float64(width)
+		cam.ViewportPosY = cam.Viewport.Y * float64(height)
+		cam.ViewportSizeX = cam.Viewport.W * float64(width)
+		cam.ViewportSizeY = cam.Viewport.H * float64(height)
+	})
+}
+
+// SortedCameras returns all cameras ordered by ascending priority (draw order)
+func SortedCameras(w donburi.World) []*donburi.Entry {
+	var cams []*donburi.Entry
+	Camera.Each(w, func(entry *donburi.Entry) {
+		cams = append(cams, entry)
+	})
+	sort.SliceStable(cams, func(i, j int) bool {
+		return Camera.Get(cams[i]).Priority < Camera.Get(cams[j]).Priority
+	})
+	return cams
+}
+
+// MainCamera returns the camera with the highest priority
+func MainCamera(w donburi.World) *donburi.Entry {
+	cams := SortedCameras(w)
+	if len(cams) == 0 {
+		return nil
+	}
+	return cams[len(cams)-1]
+}
+
+// CameraAt returns the topmost camera whose viewport contains the screen point,
+// falling back to the main camera
+func CameraAt(w donburi.World, p Vec2.Vec2) *donburi.Entry {
+	cams := SortedCameras(w)
+	for i := len(cams) - 1; i >= 0; i-- {
+		if Camera.Get(cams[i]).ContainsScreenPoint(p) {
+			return cams[i]
+		}
+	}
+	return MainCamera(w)
+}
+
 func ChangeZoom(w donburi.World, new_zoom Vec2.Vec2) {
-	cam_entry, _ := Camera.First(w)
+	cam_entry := MainCamera(w)
+	if cam_entry == nil {
+		return
+	}
 	old_cam_obj := Camera.Get(cam_entry)
 	old_cam_obj.Zoom = new_zoom
 }
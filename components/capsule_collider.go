@@ -0,0 +1,22 @@
+package components
+
+import (
+	Vec2 "physengine/helpers/vec2"
+
+	"github.com/yohamta/donburi"
+)
+
+// CapsuleColliderData is a segment of length 2*HalfLength along the local Y axis inflated by Radius
+type CapsuleColliderData struct {
+	Radius     float64
+	HalfLength float64   // Half the distance between the cap centres
+	Offset     Vec2.Vec2 // Centre of the capsule relative to the body position
+	Rot        float64   // Local rotation, 0 keeps the capsule upright
+}
+
+var CapsuleCollider = donburi.NewComponentType[CapsuleColliderData]()
+
+// Shape returns the capsule as a body-space collision shape
+func (c *CapsuleColliderData) Shape() Shape {
+	return Shape{Kind: ShapeCapsule, Offset: c.Offset, Rot: c.Rot, Radius: c.Radius, HalfLength: c.HalfLength}
+}
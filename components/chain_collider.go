@@ -0,0 +1,81 @@
+package components
+
+import (
+	Vec2 "physengine/helpers/vec2"
+
+	"github.com/yohamta/donburi"
+)
+
+// ChainColliderData is a polyline of one-sided segments for static level geometry. Every
+// segment knows the vertices of its neighbours (ghost vertices), so bodies slide across
+// the joints instead of catching on them. The solid side is on the right of the point
+// order: list ground left to right, and loops around solid areas clockwise.
+type ChainColliderData struct {
+	Points []Vec2.Vec2 // Body space
+	Loop   bool        // Connect the last point back to the first
+	Radius float64
+}
+
+var ChainCollider = donburi.NewComponentType[ChainColliderData]()
+
+// Shapes returns the segments of the chain in body space
+func (c *ChainColliderData) Shapes() []Shape {
+	return ChainShapes(c.Points, c.Loop, c.Radius)
+}
+
+// ChainShapes splits a polyline into one-sided segment shapes that carry their neighbouring
+// vertices. Open chains have no ghost at their two ends.
+func ChainShapes(points []Vec2.Vec2, loop bool, radius float64) []Shape {
+	count := len(points)
+	if count < 2 {
+		return nil
+	}
+	segments := count - 1
+	if loop {
+		segments = count
+	}
+	shapes := make([]Shape, 0, segments)
+	for i := 0; i < segments; i++ {
+		s := Shape{
+			Kind:     ShapeSegment,
+			A:        points[i],
+			B:        points[(i+1)%count],
+			Radius:   radius,
+			OneSided: true,
+		}
+		if loop || i > 0 {
+			s.Prev = points[(i-1+count)%count]
+			s.HasPrev = true
+		}
+		if loop || i+2 < count {
+			s.Next = points[(i+2)%count]
+			s.HasNext = true
+		}
+		shapes = append(shapes, s)
+	}
+	return shapes
+}
+
+// ClockwiseLoop returns the points of a closed polygon in clockwise order, so a chain
+// loop built from them is solid on the inside
+func ClockwiseLoop(points []Vec2.Vec2) []Vec2.Vec2 {
+	if signedArea(points) <= 0 {
+		return points
+	}
+	reversed := make([]Vec2.Vec2, len(points))
+	for i, p := range points {
+		reversed[len(points)-1-i] = p
+	}
+	return reversed
+}
+
+// signedArea is positive for counter-clockwise polygons in the Y-up world
+func signedArea(points []Vec2.Vec2) float64 {
+	area := 0.0
+	for i := range points {
+		a := points[i]
+		b := points[(i+1)%len(points)]
+		area += a.X*b.Y - b.X*a.Y
+	}
+	return area / 2
+}
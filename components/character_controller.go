@@ -0,0 +1,71 @@
+package components
+
+import (
+	"math"
+	Vec2 "physengine/helpers/vec2"
+
+	"github.com/yohamta/donburi"
+)
+
+// CharacterInput is read by the character controller every step, so a character can be
+// driven by the keyboard, AI or a replay
+type CharacterInput interface {
+	MoveAxis() float64 // -1 full left, 1 full right
+	JumpPressed() bool // True only on the step the jump button went down
+	JumpHeld() bool    // Releasing early cuts the jump short
+}
+
+// CharacterControllerData moves a kinematic body with move-and-slide instead of impulses.
+// The entity's own Velocity is written for other bodies to react to, but it is not
+// integrated by UpdateIntegration.
+type CharacterControllerData struct {
+	Input CharacterInput
+
+	MoveSpeed       float64 // Horizontal speed at full input
+	Acceleration    float64 // Horizontal acceleration on the ground
+	AirAcceleration float64
+	JumpSpeed       float64
+	Gravity         float64 // Downward acceleration, independent of the world gravity
+	MaxFallSpeed    float64
+	MaxSlope        float64 // Steepest walkable ground in radians
+	StepHeight      float64 // Ledges up to this height are climbed without jumping
+	CoyoteTime      float64 // Seconds after leaving ground during which a jump still works
+	JumpBufferTime  float64 // Seconds a jump press is remembered before landing
+	SkinWidth       float64 // Gap kept between the character and what it touches
+
+	// State
+	Velocity        Vec2.Vec2 // Own movement, without the motion of the ground
+	Grounded        bool
+	GroundNormal    Vec2.Vec2
+	Ground          *donburi.Entry // Body stood on, carries the character when it moves
+	CoyoteTimer     float64
+	JumpBufferTimer float64
+}
+
+var CharacterController = donburi.NewComponentType[CharacterControllerData](CharacterControllerData{
+	MoveSpeed:       300,
+	Acceleration:    2500,
+	AirAcceleration: 1200,
+	JumpSpeed:       650,
+	Gravity:         1600,
+	MaxFallSpeed:    1200,
+	MaxSlope:        50 * math.Pi / 180,
+	StepHeight:      20,
+	CoyoteTime:      0.1,
+	JumpBufferTime:  0.12,
+	SkinWidth:       0.5,
+})
+
+// Walkable reports whether ground with the given normal is flat enough to stand on
+func (c *CharacterControllerData) Walkable(normal Vec2.Vec2) bool {
+	return normal.Y >= math.Cos(c.MaxSlope)
+}
+
+// Supports reports whether a hit can carry the character: walkable ground, or the corner
+// of a ledge low enough to step onto, which rounded shapes meet at a steep angle
+func (c *CharacterControllerData) Supports(hit ShapeCastHit, feetY float64) bool {
+	if c.Walkable(hit.Normal) {
+		return true
+	}
+	return hit.Corner && hit.Normal.Y > 0 && hit.Point.Y-feetY <= c.StepHeight
+}
@@ -10,29 +10,32 @@ import (
 
 type CircleColliderData struct {
 	Radius            float64
+	Offset            Vec2.Vec2 // Centre of the circle relative to the body position
 }
 
 var CircleCollider = donburi.NewComponentType[CircleColliderData]()
 
+// segmentPickTolerance lets thin segments be picked with the mouse
+const segmentPickTolerance = 5.0
+
+// PosInsideCollider reports whether a world position lies inside any collision shape of the entity
 func PosInsideCollider(entry *donburi.Entry, pos Vec2.Vec2) bool {
 	if !entry.HasComponent(Transform) {
 		fmt.Println("PosInsideCollider: missing transform component")
 		return false
 	}
-	if !entry.HasComponent(CircleCollider) {
-		fmt.Println("PosInsideCollider: missing circle collider component")
-		return false
-	}
-	tr := Transform.Get(entry)
-	cc := CircleCollider.Get(entry)
-
-	if tr == nil || cc == nil {
-		return false
+	for _, shape := range WorldShapes(entry) {
+		if shape.Kind == ShapeSegment {
+			shape.Radius = math.Max(shape.Radius, segmentPickTolerance)
+		}
+		if shape.ContainsPoint(pos) {
+			return true
+		}
 	}
-
-	return math.Pow(pos.X-tr.Pos.X, 2)+math.Pow(pos.Y-tr.Pos.Y, 2) < math.Pow(cc.Radius, 2)
+	return false
 }
 
+// CirclesCollide reports whether the circles of two bodies overlap, honouring their offsets
 func CirclesCollide(e1, e2 *donburi.Entry) bool {
 	if (!e1.HasComponent(Transform)) || (!e2.HasComponent(Transform)) {
 		fmt.Println("CirclesCollide: missing transform component")
@@ -42,21 +45,11 @@ func CirclesCollide(e1, e2 *donburi.Entry) bool {
 		fmt.Println("CirclesCollide: missing circle collider component")
 		return false
 	}
+	_, ok := CollideShapes(entryShape(e1, CircleCollider.Get(e1).Shape()), entryShape(e2, CircleCollider.Get(e2).Shape()))
+	return ok
+}
 
-	tr1 := Transform.Get(e1)
-	tr2 := Transform.Get(e2)
-	c1 := CircleCollider.Get(e1)
-	c2 := CircleCollider.Get(e2)
-
-	if tr1 == nil || tr2 == nil || c1 == nil || c2 == nil {
-		return false
-	}
-
-	// Calculate distance between centers
-	dx := tr1.Pos.X - tr2.Pos.X
-	dy := tr1.Pos.Y - tr2.Pos.Y
-	dist_squared := dx*dx + dy*dy
-	radius_sum_squared := (c1.Radius + c2.Radius) * (c1.Radius + c2.Radius)
-
-	return dist_squared < radius_sum_squared
+// Shape returns the circle as a body-space collision shape
+func (c *CircleColliderData) Shape() Shape {
+	return Shape{Kind: ShapeCircle, Offset: c.Offset, Radius: c.Radius}
 }
@@ -1,11 +1,62 @@
 package components
 
 import (
+	Vec2 "physengine/helpers/vec2"
+	"time"
+
 	"github.com/yohamta/donburi"
 )
 
+// ContactData describes one resolved contact from the last collision step
+type ContactData struct {
+	EntryA      *donburi.Entry
+	EntryB      *donburi.Entry
+	Point       Vec2.Vec2
+	Normal      Vec2.Vec2 // Points from EntryA to EntryB
+	Penetration float64
+	Impulse     float64 // Normal impulse applied to separate the bodies
+}
+
+// BroadphaseProxy is the world-space bounding box of one collision shape
+type BroadphaseProxy struct {
+	Entry    *donburi.Entry
+	Shape    WorldShape
+	Min, Max Vec2.Vec2
+	Static   bool
+}
+
 type CollisionResolverData struct {
 	Physobs []*donburi.Entry
+
+	// Shapes of static bodies, computed once and reused until the static set changes
+	StaticProxies []BroadphaseProxy
+	staticBodies  int
+	staticDirty   bool
+
+	// Statistics of the last step, used by the debug overlay
+	Contacts     []ContactData
+	PairCount    int
+	StepDuration time.Duration
 }
 
 var CollisionResolverComponent = donburi.NewComponentType[CollisionResolverData]()
+
+// StaticCacheValid reports whether the cached static proxies still match staticBodies static entities
+func (c *CollisionResolverData) StaticCacheValid(staticBodies int) bool {
+	return !c.staticDirty && c.staticBodies == staticBodies && c.StaticProxies != nil
+}
+
+// SetStaticProxies replaces the cached static proxies
+func (c *CollisionResolverData) SetStaticProxies(proxies []BroadphaseProxy, staticBodies int) {
+	c.StaticProxies = proxies
+	c.staticBodies = staticBodies
+	c.staticDirty = false
+}
+
+// InvalidateStaticBroadphase makes the broadphase recompute static shapes on the next step,
+// needed after moving a static body or editing its colliders
+func InvalidateStaticBroadphase(w donburi.World) {
+	if entry, ok := CollisionResolverComponent.First(w); ok {
+		CollisionResolverComponent.Get(entry).staticDirty = true
+	}
+}
@@ -0,0 +1,204 @@
+package components
+
+import (
+	"fmt"
+	"math"
+	Vec2 "physengine/helpers/vec2"
+)
+
+// DecomposePolygon splits a simple polygon (concave allowed, any winding, no holes) into
+// convex counter-clockwise pieces: ear clipping triangulates it, then Hertel–Mehlhorn
+// removes every diagonal whose two sides still form a convex piece when merged.
+func DecomposePolygon(points []Vec2.Vec2) ([][]Vec2.Vec2, error) {
+	poly := cleanPolygon(points)
+	if len(poly) < 3 {
+		return nil, fmt.Errorf("polygon needs at least 3 distinct points, got %d", len(poly))
+	}
+	if signedArea(poly) < 0 {
+		poly = reversedPoints(poly)
+	}
+	if IsConvex(poly) {
+		return [][]Vec2.Vec2{poly}, nil
+	}
+
+	triangles, err := triangulate(poly)
+	if err != nil {
+		return nil, err
+	}
+	pieces := mergeConvexPieces(poly, triangles)
+
+	result := make([][]Vec2.Vec2, len(pieces))
+	for i, piece := range pieces {
+		result[i] = make([]Vec2.Vec2, len(piece))
+		for j, index := range piece {
+			result[i][j] = poly[index]
+		}
+	}
+	return result, nil
+}
+
+// PolygonShapes decomposes a polygon and returns its convex pieces as body-space shapes
+func PolygonShapes(points []Vec2.Vec2) ([]Shape, error) {
+	pieces, err := DecomposePolygon(points)
+	if err != nil {
+		return nil, err
+	}
+	shapes := make([]Shape, len(pieces))
+	for i, piece := range pieces {
+		shapes[i] = Shape{Kind: ShapePolygon, Vertices: piece}
+	}
+	return shapes, nil
+}
+
+// IsConvex reports whether a counter-clockwise polygon is convex
+func IsConvex(poly []Vec2.Vec2) bool {
+	for i := range poly {
+		if turnAt(poly, i) < -narrowphaseEpsilon {
+			return false
+		}
+	}
+	return true
+}
+
+// turnAt is positive where a counter-clockwise polygon turns left at vertex i
+func turnAt(poly []Vec2.Vec2, i int) float64 {
+	n := len(poly)
+	a, b, c := poly[(i-1+n)%n], poly[i], poly[(i+1)%n]
+	return Vec2.CrossProductVecVec(b.Add(a.Mult(-1)), c.Add(b.Mult(-1)))
+}
+
+// cleanPolygon drops repeated and collinear points
+func cleanPolygon(points []Vec2.Vec2) []Vec2.Vec2 {
+	var poly []Vec2.Vec2
+	for _, p := range points {
+		if len(poly) == 0 || Vec2.Distance(poly[len(poly)-1], p) > narrowphaseEpsilon {
+			poly = append(poly, p)
+		}
+	}
+	if len(poly) > 1 && Vec2.Distance(poly[0], poly[len(poly)-1]) <= narrowphaseEpsilon {
+		poly = poly[:len(poly)-1]
+	}
+	for removed := true; removed && len(poly) >= 3; {
+		removed = false
+		for i := range poly {
+			if math.Abs(turnAt(poly, i)) <= narrowphaseEpsilon {
+				poly = append(poly[:i], poly[i+1:]...)
+				removed = true
+				break
+			}
+		}
+	}
+	return poly
+}
+
+func reversedPoints(points []Vec2.Vec2) []Vec2.Vec2 {
+	reversed := make([]Vec2.Vec2, len(points))
+	for i, p := range points {
+		reversed[len(points)-1-i] = p
+	}
+	return reversed
+}
+
+// triangulate ear-clips a counter-clockwise polygon into triangles of vertex indices
+func triangulate(poly []Vec2.Vec2) ([][]int, error) {
+	remaining := make([]int, len(poly))
+	for i := range remaining {
+		remaining[i] = i
+	}
+
+	var triangles [][]int
+	for len(remaining) > 3 {
+		clipped := false
+		for i := range remaining {
+			n := len(remaining)
+			prev, cur, next := remaining[(i-1+n)%n], remaining[i], remaining[(i+1)%n]
+			if !isEar(poly, remaining, prev, cur, next) {
+				continue
+			}
+			triangles = append(triangles, []int{prev, cur, next})
+			remaining = append(remaining[:i], remaining[i+1:]...)
+			clipped = true
+			break
+		}
+		if !clipped {
+			return nil, fmt.Errorf("polygon could not be triangulated, it may intersect itself")
+		}
+	}
+	return append(triangles, remaining), nil
+}
+
+// isEar reports whether the triangle prev-cur-next is convex and holds no other vertex
+func isEar(poly []Vec2.Vec2, remaining []int, prev, cur, next int) bool {
+	a, b, c := poly[prev], poly[cur], poly[next]
+	if Vec2.CrossProductVecVec(b.Add(a.Mult(-1)), c.Add(b.Mult(-1))) <= narrowphaseEpsilon {
+		return false
+	}
+	for _, index := range remaining {
+		if index == prev || index == cur || index == next {
+			continue
+		}
+		if pointInTriangle(poly[index], a, b, c) {
+			return false
+		}
+	}
+	return true
+}
+
+// pointInTriangle includes the edges, so ears touching another vertex are rejected
+func pointInTriangle(p, a, b, c Vec2.Vec2) bool {
+	d1 := Vec2.CrossProductVecVec(b.Add(a.Mult(-1)), p.Add(a.Mult(-1)))
+	d2 := Vec2.CrossProductVecVec(c.Add(b.Mult(-1)), p.Add(b.Mult(-1)))
+	d3 := Vec2.CrossProductVecVec(a.Add(c.Mult(-1)), p.Add(c.Mult(-1)))
+	return d1 >= 0 && d2 >= 0 && d3 >= 0
+}
+
+// mergeConvexPieces is Hertel–Mehlhorn: merge two pieces across a shared diagonal
+// whenever the result is still convex
+func mergeConvexPieces(poly []Vec2.Vec2, pieces [][]int) [][]int {
+	for merged := true; merged; {
+		merged = false
+		for i := 0; i < len(pieces) && !merged; i++ {
+			for j := i + 1; j < len(pieces) && !merged; j++ {
+				combined, ok := joinAcrossDiagonal(pieces[i], pieces[j])
+				if !ok || !indicesConvex(poly, combined) {
+					continue
+				}
+				pieces[i] = combined
+				pieces = append(pieces[:j], pieces[j+1:]...)
+				merged = true
+			}
+		}
+	}
+	return pieces
+}
+
+// joinAcrossDiagonal joins two counter-clockwise pieces that share an edge; p runs a->b
+// along it and q runs b->a
+func joinAcrossDiagonal(p, q []int) ([]int, bool) {
+	for i := range p {
+		a, b := p[i], p[(i+1)%len(p)]
+		for k := range q {
+			if q[k] != b || q[(k+1)%len(q)] != a {
+				continue
+			}
+			// Walk p from b round to a, then q from a round to b, skipping the shared ends
+			combined := make([]int, 0, len(p)+len(q)-2)
+			for step := 1; step <= len(p); step++ {
+				combined = append(combined, p[(i+step)%len(p)])
+			}
+			for step := 2; step < len(q); step++ {
+				combined = append(combined, q[(k+step)%len(q)])
+			}
+			return combined, true
+		}
+	}
+	return nil, false
+}
+
+func indicesConvex(poly []Vec2.Vec2, indices []int) bool {
+	points := make([]Vec2.Vec2, len(indices))
+	for i, index := range indices {
+		points[i] = poly[index]
+	}
+	return IsConvex(points)
+}
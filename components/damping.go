@@ -0,0 +1,20 @@
+package components
+
+import "github.com/yohamta/donburi"
+
+// DampingData holds exponential damping rates in 1/s; velocities decay as v·e^(-rate·dt).
+// Bodies without it use the PhysicsWorld defaults.
+type DampingData struct {
+	Linear  float64
+	Angular float64
+}
+
+var Damping = donburi.NewComponentType[DampingData]()
+
+// AirDragData enables quadratic air drag, F = ½·ρ·Cd·A·|v|², where A is the
+// body's cross-section perpendicular to its velocity
+type AirDragData struct {
+	Coefficient float64 // Drag coefficient Cd
+}
+
+var AirDrag = donburi.NewComponentType[AirDragData]()
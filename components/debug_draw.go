@@ -0,0 +1,19 @@
+package components
+
+import "github.com/yohamta/donburi"
+
+// DebugDrawData holds the toggles of the physics debug overlay
+type DebugDrawData struct {
+	Shapes       bool // Collider outlines
+	Contacts     bool // Contact points of the last collision step
+	Normals      bool // Contact normals
+	Velocities   bool // Linear velocity vectors
+	Bounds       bool // World-space axis-aligned bounding boxes
+	CenterOfMass bool // Centre of mass markers
+	Stats        bool // On-screen stats HUD
+	Joints       bool // Joint anchors and axes
+	Broadphase   bool // Bounds of the broadphase proxies
+	Sleep        bool // Sleeping bodies outlined in their own colour
+}
+
+var DebugDraw = donburi.NewComponentType[DebugDrawData]()
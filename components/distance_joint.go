@@ -0,0 +1,20 @@
+package components
+
+import (
+	Vec2 "physengine/helpers/vec2"
+
+	"github.com/yohamta/donburi"
+)
+
+// DistanceJointData keeps two anchor points at a fixed distance. A rope joint only stops
+// the anchors moving further apart and lets them come closer.
+type DistanceJointData struct {
+	LocalAnchorA Vec2.Vec2
+	LocalAnchorB Vec2.Vec2
+	Length       float64
+	Rope         bool
+
+	Impulse float64 // Impulse along the joint in the last step, reused to warm start the next one
+}
+
+var DistanceJoint = donburi.NewComponentType[DistanceJointData]()
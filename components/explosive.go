@@ -0,0 +1,40 @@
+package components
+
+import "github.com/yohamta/donburi"
+
+// ExplosionFalloff is how an explosion's impulse weakens with distance from its centre
+type ExplosionFalloff int
+
+const (
+	FalloffNone      ExplosionFalloff = iota // Full impulse everywhere inside the radius
+	FalloffLinear                            // Fades linearly to zero at the radius
+	FalloffQuadratic                         // Fades with the square of the distance, strongest close in
+)
+
+// Scale returns the fraction of the impulse felt at dist from the centre of an explosion
+// of the given radius
+func (f ExplosionFalloff) Scale(dist, radius float64) float64 {
+	if radius <= 0 || dist > radius {
+		return 0
+	}
+	t := 1 - dist/radius
+	switch f {
+	case FalloffLinear:
+		return t
+	case FalloffQuadratic:
+		return t * t
+	}
+	return 1
+}
+
+// ExplosiveData makes an entity blow up at its position once its fuse runs out. The entity
+// is removed when it explodes.
+type ExplosiveData struct {
+	Fuse     float64 // Seconds left before the explosion
+	Radius   float64
+	Impulse  float64 // Impulse at the centre, before falloff
+	Falloff  ExplosionFalloff
+	Occluded bool // Bodies hidden behind other shapes are sheltered from the blast
+}
+
+var Explosive = donburi.NewComponentType[ExplosiveData]()
@@ -0,0 +1,80 @@
+package components
+
+import (
+	"image/color"
+	"math"
+	Vec2 "physengine/helpers/vec2"
+
+	"github.com/yohamta/donburi"
+)
+
+// FluidParticle is one SPH particle, in world space
+type FluidParticle struct {
+	Pos      Vec2.Vec2
+	Vel      Vec2.Vec2
+	Density  float64 // Smoothed density from the last step
+	Pressure float64
+}
+
+// FluidRender selects how a fluid is drawn
+type FluidRender int
+
+const (
+	FluidMetaballs FluidRender = iota // Blended blobs cut at a threshold, for liquids
+	FluidGrains                       // One square per particle, for granular material
+)
+
+// FluidSettings holds the material constants of a fluid
+type FluidSettings struct {
+	Name            string
+	RestDensity     float64 // Mass per unit area at rest, comparable to MaterialData.Density
+	Stiffness       float64 // Pressure per unit of excess density; its square root is the speed of sound
+	Viscosity       float64 // Kinematic viscosity, higher is thicker
+	SmoothingRadius float64 // Reach of the SPH kernels, about twice the particle spacing
+	Friction        float64 // Coulomb friction against rigid bodies
+	Render          FluidRender
+	Color           color.RGBA
+}
+
+// FluidPresets are ready-made fluids by name
+var FluidPresets = map[string]FluidSettings{
+	"water": {Name: "water", RestDensity: 0.001, Stiffness: 1e6, Viscosity: 1500, SmoothingRadius: 24, Friction: 0.05,
+		Render: FluidMetaballs, Color: color.RGBA{R: 40, G: 110, B: 210, A: 220}},
+	"sand": {Name: "sand", RestDensity: 0.0016, Stiffness: 1e6, Viscosity: 40000, SmoothingRadius: 20, Friction: 0.9,
+		Render: FluidGrains, Color: color.RGBA{R: 210, G: 180, B: 110, A: 255}},
+}
+
+// FluidPreset returns a named fluid preset
+func FluidPreset(name string) (FluidSettings, bool) {
+	settings, ok := FluidPresets[name]
+	return settings, ok
+}
+
+// FluidData is a body of smoothed-particle hydrodynamics fluid. Particles push on rigid
+// bodies they touch and are pushed back, so bodies float, sink and are slowed by it.
+type FluidData struct {
+	FluidSettings
+	Particles      []FluidParticle
+	ParticleMass   float64
+	ParticleRadius float64 // Collision radius against rigid bodies
+}
+
+var Fluid = donburi.NewComponentType[FluidData]()
+
+// AddParticle appends a particle at rest at pos
+func (f *FluidData) AddParticle(pos Vec2.Vec2) {
+	f.Particles = append(f.Particles, FluidParticle{Pos: pos, Density: f.RestDensity})
+}
+
+// Bounds returns the world-space box around all particles, grown by the particle radius
+func (f *FluidData) Bounds() (Vec2.Vec2, Vec2.Vec2) {
+	min := Vec2.Vec2{X: math.Inf(1), Y: math.Inf(1)}
+	max := Vec2.Vec2{X: math.Inf(-1), Y: math.Inf(-1)}
+	for _, p := range f.Particles {
+		min.X = math.Min(min.X, p.Pos.X-f.ParticleRadius)
+		min.Y = math.Min(min.Y, p.Pos.Y-f.ParticleRadius)
+		max.X = math.Max(max.X, p.Pos.X+f.ParticleRadius)
+		max.Y = math.Max(max.Y, p.Pos.Y+f.ParticleRadius)
+	}
+	return min, max
+}
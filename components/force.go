@@ -0,0 +1,42 @@
+package components
+
+import (
+	Vec2 "physengine/helpers/vec2"
+
+	"github.com/yohamta/donburi"
+)
+
+// ForceData accumulates the forces applied to a body during a frame. The integrator turns
+// it into acceleration and clears it, like Torque.
+type ForceData struct {
+	Force Vec2.Vec2
+}
+
+var Force = donburi.NewComponentType[ForceData]()
+
+// AddForce adds a force through the centre of mass for the next integration step. Bodies
+// without a Force component get one, so do not call it while iterating a query.
+func AddForce(entry *donburi.Entry, force Vec2.Vec2) {
+	if !entry.HasComponent(Force) {
+		entry.AddComponent(Force)
+	}
+	Force.Get(entry).Force.AddUpdate(force)
+}
+
+// AddForceAtPoint adds a force applied at a world point, along with the torque it exerts
+// about the centre of mass
+func AddForceAtPoint(entry *donburi.Entry, force Vec2.Vec2, point Vec2.Vec2) {
+	AddForce(entry, force)
+	if entry.HasComponent(Torque) {
+		r := point.Add(Transform.Get(entry).Pos.Mult(-1))
+		AddTorque(entry, Vec2.CrossProductVecVec(r, force))
+	}
+}
+
+// GetForce returns the force accumulated so far this frame
+func GetForce(entry *donburi.Entry) Vec2.Vec2 {
+	if !entry.HasComponent(Force) {
+		return Vec2.Vec2{}
+	}
+	return Force.Get(entry).Force
+}
@@ -0,0 +1,147 @@
+package components
+
+import (
+	"math"
+	"math/rand"
+	Vec2 "physengine/helpers/vec2"
+
+	"github.com/yohamta/donburi"
+)
+
+// BreakableData makes a body shatter into fragments when a single contact pushes on it
+// harder than Strength. Fragments keep the body's material, motion and sprite.
+type BreakableData struct {
+	Strength  float64 // Normal contact impulse that breaks the body
+	Fragments int     // Voronoi cells, or wedges for a circle
+	Depth     int     // How many more times the fragments can break in turn
+	MinArea   float64 // Fragments smaller than this never break again
+}
+
+var Breakable = donburi.NewComponentType[BreakableData](BreakableData{
+	Strength:  400,
+	Fragments: 6,
+	MinArea:   400,
+})
+
+// minFragmentArea drops slivers that would make unstable bodies
+const minFragmentArea = 1.0
+
+// FractureShapes splits the body-space shapes of a body into count fragments broken
+// around an impact point, also in body space. Each fragment is a list of convex polygon
+// shapes in body space. A lone circle splits into wedges meeting at its centre; anything
+// else splits into Voronoi cells whose sites cluster around the impact. Shapes without
+// area, such as segments, are dropped.
+func FractureShapes(shapes []Shape, impact Vec2.Vec2, count int) [][]Shape {
+	if count < 2 {
+		return nil
+	}
+	var pieces [][]Vec2.Vec2
+	var densities []float64
+	for _, s := range shapes {
+		if s.Kind == ShapeSegment {
+			continue
+		}
+		if s.Kind == ShapeCircle && len(shapes) == 1 {
+			return circleWedges(s, impact, count)
+		}
+		outline := ShapeOutline(s.ToWorld(Vec2.Vec2{}, 0))
+		if len(outline) >= 3 {
+			pieces = append(pieces, outline)
+			densities = append(densities, s.Density)
+		}
+	}
+	if len(pieces) == 0 {
+		return nil
+	}
+
+	sites := fractureSites(pieces, impact, count)
+	fragments := make([][]Shape, 0, len(sites))
+	for i, site := range sites {
+		var fragment []Shape
+		for p, piece := range pieces {
+			cell := piece
+			for j, other := range sites {
+				if j == i || len(cell) < 3 {
+					continue
+				}
+				// Keep the side of the bisector nearer to this site
+				cell = clipHalfPlane(cell, site.Add(other).Mult(0.5), other.Add(site.Mult(-1)))
+			}
+			if len(cell) >= 3 && signedArea(cell) > minFragmentArea {
+				fragment = append(fragment, Shape{Kind: ShapePolygon, Vertices: cell, Density: densities[p]})
+			}
+		}
+		if len(fragment) > 0 {
+			fragments = append(fragments, fragment)
+		}
+	}
+	return fragments
+}
+
+// fractureSites scatters Voronoi sites over convex pieces, half of them close to the impact
+// so the break is finer where the body was hit
+func fractureSites(pieces [][]Vec2.Vec2, impact Vec2.Vec2, count int) []Vec2.Vec2 {
+	min, max, _ := pointsBounds(pieces[0])
+	for _, piece := range pieces[1:] {
+		pMin, pMax, _ := pointsBounds(piece)
+		min = Vec2.Vec2{X: math.Min(min.X, pMin.X), Y: math.Min(min.Y, pMin.Y)}
+		max = Vec2.Vec2{X: math.Max(max.X, pMax.X), Y: math.Max(max.Y, pMax.Y)}
+	}
+	spread := math.Max(max.X-min.X, max.Y-min.Y) / 4
+
+	inside := func(p Vec2.Vec2) bool {
+		for _, piece := range pieces {
+			if pointInConvexPolygon(p, piece) {
+				return true
+			}
+		}
+		return false
+	}
+	sites := make([]Vec2.Vec2, 0, count)
+	for tries := 0; len(sites) < count && tries < count*50; tries++ {
+		var p Vec2.Vec2
+		if len(sites) < count/2 {
+			p = impact.Add(Vec2.Vec2{X: rand.NormFloat64() * spread, Y: rand.NormFloat64() * spread})
+		} else {
+			p = Vec2.Vec2{X: min.X + rand.Float64()*(max.X-min.X), Y: min.Y + rand.Float64()*(max.Y-min.Y)}
+		}
+		if inside(p) {
+			sites = append(sites, p)
+		}
+	}
+	return sites
+}
+
+// circleWedges cuts a circle into count equal wedges, the first seam pointing at the impact
+func circleWedges(s Shape, impact Vec2.Vec2, count int) [][]Shape {
+	start := math.Atan2(impact.Y-s.Offset.Y, impact.X-s.Offset.X)
+	steps := max(outlineSegments/count, 2)
+	fragments := make([][]Shape, 0, count)
+	for i := 0; i < count; i++ {
+		wedge := []Vec2.Vec2{s.Offset}
+		for k := 0; k <= steps; k++ {
+			angle := start + 2*math.Pi*(float64(i)+float64(k)/float64(steps))/float64(count)
+			wedge = append(wedge, s.Offset.Add(Vec2.Vec2{X: math.Cos(angle) * s.Radius, Y: math.Sin(angle) * s.Radius}))
+		}
+		fragments = append(fragments, []Shape{{Kind: ShapePolygon, Vertices: wedge, Density: s.Density}})
+	}
+	return fragments
+}
+
+// clipHalfPlane returns the part of a convex polygon on the side of the line through origin
+// that normal points away from
+func clipHalfPlane(poly []Vec2.Vec2, origin, normal Vec2.Vec2) []Vec2.Vec2 {
+	side := func(p Vec2.Vec2) float64 { return Vec2.DotProduct(p.Add(origin.Mult(-1)), normal) }
+	clipped := make([]Vec2.Vec2, 0, len(poly)+1)
+	for i := range poly {
+		p, q := poly[i], poly[(i+1)%len(poly)]
+		sp, sq := side(p), side(q)
+		if sp <= 0 {
+			clipped = append(clipped, p)
+		}
+		if (sp <= 0) != (sq <= 0) {
+			clipped = append(clipped, p.Add(q.Add(p.Mult(-1)).Mult(sp/(sp-sq))))
+		}
+	}
+	return clipped
+}
@@ -0,0 +1,18 @@
+package components
+
+import "github.com/yohamta/donburi"
+
+// GravityScaleData scales the world gravity for one body. Bodies without it use a scale of 1.
+type GravityScaleData struct {
+	Scale float64
+}
+
+var GravityScale = donburi.NewComponentType[GravityScaleData]()
+
+// GetGravityScale returns the gravity scale of an entity, or 1 if it has none
+func GetGravityScale(entry *donburi.Entry) float64 {
+	if !entry.HasComponent(GravityScale) {
+		return 1
+	}
+	return GravityScale.Get(entry).Scale
+}
@@ -0,0 +1,66 @@
+package components
+
+import (
+	Vec2 "physengine/helpers/vec2"
+
+	"github.com/yohamta/donburi"
+)
+
+// Integrator selects how UpdateIntegration advances bodies through a step
+type Integrator int
+
+const (
+	// IntegratorSemiImplicitEuler updates velocity, then position from the new velocity.
+	// It is symplectic, so orbits keep their energy on average, and costs one force
+	// evaluation. Being first order, it loses ½·m·|g|²·dt² in every step under constant
+	// gravity, falling freely or resting alike, which shows as a steady drift.
+	IntegratorSemiImplicitEuler Integrator = iota
+	// IntegratorVelocityVerlet is second order and symplectic, at two force evaluations
+	IntegratorVelocityVerlet
+	// IntegratorRK4 is fourth order but not symplectic, at four force evaluations
+	IntegratorRK4
+	integratorCount
+)
+
+func (i Integrator) String() string {
+	switch i {
+	case IntegratorSemiImplicitEuler:
+		return "semi-implicit Euler"
+	case IntegratorVelocityVerlet:
+		return "velocity Verlet"
+	case IntegratorRK4:
+		return "RK4"
+	}
+	return "unknown"
+}
+
+// Next returns the integrator after i, wrapping around
+func (i Integrator) Next() Integrator {
+	return (i + 1) % integratorCount
+}
+
+// IntegrationStatsData tracks the mechanical energy and momentum of the integrated bodies.
+// Damping, contacts and joints change the energy outside the integrator, so the drift only
+// counts the energy each integration step gains or loses beyond the work of applied forces,
+// which is the integrator's own error.
+type IntegrationStatsData struct {
+	Kinetic         float64 // Linear and angular kinetic energy after the last step
+	Potential       float64 // Potential energy of uniform gravity and attractors after the last step
+	Momentum        Vec2.Vec2
+	AngularMomentum float64 // About the world origin, including spin
+	StepDrift       float64 // Energy error of the last step
+	Drift           float64 // Energy error summed over all steps since the reset
+	Steps           int
+}
+
+var IntegrationStats = donburi.NewComponentType[IntegrationStatsData]()
+
+// Energy returns the total mechanical energy after the last step
+func (s *IntegrationStatsData) Energy() float64 {
+	return s.Kinetic + s.Potential
+}
+
+// Reset clears the accumulated drift, for example after switching integrators
+func (s *IntegrationStatsData) Reset() {
+	s.StepDrift, s.Drift, s.Steps = 0, 0, 0
+}
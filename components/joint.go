@@ -0,0 +1,78 @@
+package components
+
+import (
+	Vec2 "physengine/helpers/vec2"
+
+	"github.com/yohamta/donburi"
+)
+
+// JointData connects two bodies. Joint entities have no transform of their own; the joint
+// kind is given by the other components on the entity, such as WheelJoint.
+type JointData struct {
+	BodyA            *donburi.Entry
+	BodyB            *donburi.Entry
+	CollideConnected bool    // Let the two bodies keep colliding with each other
+	BreakForce       float64 // The joint is removed when its reaction force exceeds this, 0 never breaks
+	ReactionForce    float64 // Linear force the joint applied in the last step
+}
+
+var Joint = donburi.NewComponentType[JointData]()
+
+// Valid reports whether both connected bodies still exist
+func (j *JointData) Valid() bool {
+	return j.BodyA != nil && j.BodyB != nil && j.BodyA.Valid() && j.BodyB.Valid()
+}
+
+// JointPair is an unordered pair of bodies, used to skip collisions between connected bodies
+type JointPair struct {
+	A, B donburi.Entity
+}
+
+// NewJointPair orders the two entities so (a, b) and (b, a) give the same pair
+func NewJointPair(a, b donburi.Entity) JointPair {
+	if a > b {
+		a, b = b, a
+	}
+	return JointPair{a, b}
+}
+
+// ConnectedPairs returns the body pairs linked by a joint that does not collide its bodies
+func ConnectedPairs(w donburi.World) map[JointPair]bool {
+	pairs := map[JointPair]bool{}
+	for entry := range Joint.Iter(w) {
+		joint := Joint.Get(entry)
+		if joint.CollideConnected || !joint.Valid() {
+			continue
+		}
+		pairs[NewJointPair(joint.BodyA.Entity(), joint.BodyB.Entity())] = true
+	}
+	return pairs
+}
+
+// JointAnchors returns the world positions of a joint's anchors on body A and body B
+func JointAnchors(entry *donburi.Entry) (Vec2.Vec2, Vec2.Vec2, bool) {
+	if !entry.Valid() || !entry.HasComponent(Joint) || !Joint.Get(entry).Valid() {
+		return Vec2.Vec2{}, Vec2.Vec2{}, false
+	}
+	var localA, localB Vec2.Vec2
+	switch {
+	case entry.HasComponent(WheelJoint):
+		localA, localB = WheelJoint.Get(entry).LocalAnchorA, WheelJoint.Get(entry).LocalAnchorB
+	case entry.HasComponent(DistanceJoint):
+		localA, localB = DistanceJoint.Get(entry).LocalAnchorA, DistanceJoint.Get(entry).LocalAnchorB
+	case entry.HasComponent(RevoluteJoint):
+		localA, localB = RevoluteJoint.Get(entry).LocalAnchorA, RevoluteJoint.Get(entry).LocalAnchorB
+	default:
+		return Vec2.Vec2{}, Vec2.Vec2{}, false
+	}
+	joint := Joint.Get(entry)
+	trA := Transform.Get(joint.BodyA)
+	trB := Transform.Get(joint.BodyB)
+	return trA.Pos.Add(RotatePoint(localA, trA.Rot)), trB.Pos.Add(RotatePoint(localB, trB.Rot)), true
+}
+
+// LocalAnchor converts a world point to an anchor relative to a body's position and rotation
+func LocalAnchor(body *donburi.Entry, world Vec2.Vec2) Vec2.Vec2 {
+	tr := Transform.Get(body)
+	return RotatePoint(world.Add(tr.Pos.Mult(-1)), -tr.Rot)
+}
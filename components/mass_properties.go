@@ -0,0 +1,111 @@
+package components
+
+import (
+	Vec2 "physengine/helpers/vec2"
+
+	"github.com/yohamta/donburi"
+)
+
+// ComputeMassProperties returns the total mass, the moment of inertia about the
+// centre of mass and the centre of mass (in body space) of a set of shapes.
+// Shapes without their own Density use defaultDensity.
+func ComputeMassProperties(shapes []Shape, defaultDensity float64) (float64, float64, Vec2.Vec2) {
+	mass := 0.0
+	var weighted Vec2.Vec2
+	for _, s := range shapes {
+		m := shapeDensity(s, defaultDensity) * s.Area()
+		mass += m
+		weighted.AddUpdate(s.Centroid().Mult(m))
+	}
+	if mass <= 0 {
+		return 0, 0, Vec2.Vec2{}
+	}
+	centroid := weighted.Mult(1 / mass)
+
+	// Parallel axis theorem: I = Σ (I_shape + m·d²)
+	inertia := 0.0
+	for _, s := range shapes {
+		m := shapeDensity(s, defaultDensity) * s.Area()
+		d := s.Centroid().Add(centroid.Mult(-1))
+		inertia += m*s.UnitInertia() + m*d.SquareMagnitude()
+	}
+	return mass, inertia, centroid
+}
+
+func shapeDensity(s Shape, defaultDensity float64) float64 {
+	if s.Density > 0 {
+		return s.Density
+	}
+	return defaultDensity
+}
+
+// SetMassFromShapes fills the MassComponent of an entity from its shapes. The inertia is
+// taken about the body position, so call RecenterBody first if the shapes are not centred.
+func SetMassFromShapes(entry *donburi.Entry, defaultDensity float64) {
+	if !entry.HasComponent(MassComponent) {
+		return
+	}
+	shapes := BodyShapes(entry)
+	mass, inertia, centroid := ComputeMassProperties(shapes, defaultDensity)
+	inertia += mass * centroid.SquareMagnitude()
+
+	mc := MassComponent.Get(entry)
+	mc.Mass = mass
+	mc.Inertia = inertia
+	mc.InverseMass = 0
+	mc.InverseInertia = 0
+	if mass > 0 {
+		mc.InverseMass = 1 / mass
+	}
+	if inertia > 0 {
+		mc.InverseInertia = 1 / inertia
+	}
+}
+
+// RecenterBody moves the body position to the centre of mass of its shapes and shifts the
+// collider offsets the other way, so the shapes stay in place and rotation happens about the
+// centre of mass
+func RecenterBody(entry *donburi.Entry, defaultDensity float64) {
+	_, _, centroid := ComputeMassProperties(BodyShapes(entry), defaultDensity)
+	if centroid.SquareMagnitude() == 0 {
+		return
+	}
+	shift := centroid.Mult(-1)
+
+	if entry.HasComponent(CircleCollider) {
+		crcl := CircleCollider.Get(entry)
+		crcl.Offset.AddUpdate(shift)
+	}
+	if entry.HasComponent(AABB_Component) {
+		box := AABB_Component.Get(entry)
+		box.Min.AddUpdate(shift)
+		box.Max.AddUpdate(shift)
+	}
+	if entry.HasComponent(CapsuleCollider) {
+		CapsuleCollider.Get(entry).Offset.AddUpdate(shift)
+	}
+	if entry.HasComponent(SegmentCollider) {
+		seg := SegmentCollider.Get(entry)
+		seg.A.AddUpdate(shift)
+		seg.B.AddUpdate(shift)
+	}
+	if entry.HasComponent(ChainCollider) {
+		chain := ChainCollider.Get(entry)
+		for i := range chain.Points {
+			chain.Points[i].AddUpdate(shift)
+		}
+	}
+	if entry.HasComponent(CompoundCollider) {
+		compound := CompoundCollider.Get(entry)
+		for i := range compound.Shapes {
+			compound.Shapes[i].Offset.AddUpdate(shift)
+			compound.Shapes[i].A.AddUpdate(shift)
+			compound.Shapes[i].B.AddUpdate(shift)
+			compound.Shapes[i].Prev.AddUpdate(shift)
+			compound.Shapes[i].Next.AddUpdate(shift)
+		}
+	}
+
+	tr := Transform.Get(entry)
+	tr.Pos.AddUpdate(RotatePoint(centroid, tr.Rot))
+}
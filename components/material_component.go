@@ -3,10 +3,34 @@ package components
 import "github.com/yohamta/donburi"
 
 type MaterialData struct{
+	Name string // Used to look up material pair overrides
 	Density float64
 	Restitution float64
 	StaticFriction float64
 	DynamicFriction float64
+	RestitutionCombine CombineMode
+	FrictionCombine CombineMode
 }
 
-var MaterialComponent = donburi.NewComponentType[MaterialData]()
\ No newline at end of file
+var MaterialComponent = donburi.NewComponentType[MaterialData]()
+
+// MaterialPresets are named materials for prefabs and factories.
+// Density is mass per square world unit.
+var MaterialPresets = map[string]MaterialData{
+	"rubber": {Name: "rubber", Density: 0.0011, Restitution: 0.8, StaticFriction: 0.9, DynamicFriction: 0.8, RestitutionCombine: CombineMax},
+	"steel":  {Name: "steel", Density: 0.0078, Restitution: 0.4, StaticFriction: 0.5, DynamicFriction: 0.4},
+	"ice":    {Name: "ice", Density: 0.0009, Restitution: 0.1, StaticFriction: 0.05, DynamicFriction: 0.02, FrictionCombine: CombineMin},
+	"wood":   {Name: "wood", Density: 0.0006, Restitution: 0.3, StaticFriction: 0.5, DynamicFriction: 0.4},
+	"tyre":   {Name: "tyre", Density: 0.0011, Restitution: 0.1, StaticFriction: 1.0, DynamicFriction: 0.9},
+}
+
+// MaterialPreset returns a named material preset
+func MaterialPreset(name string) (MaterialData, bool) {
+	mat, ok := MaterialPresets[name]
+	return mat, ok
+}
+
+func init() {
+	// Rubber grips poorly on ice and loses most of its bounce
+	SetMaterialPairOverride("ice", "rubber", MaterialPairOverride{Restitution: 0.2, StaticFriction: 0.15, DynamicFriction: 0.1})
+}
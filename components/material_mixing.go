@@ -0,0 +1,114 @@
+package components
+
+import "math"
+
+// CombineMode selects how the values of two touching materials are mixed
+type CombineMode int
+
+// Combine modes in ascending precedence: when two materials ask for different
+// modes, the one with the higher precedence is used
+const (
+	CombineDefault CombineMode = iota // Restitution: min, friction: geometric mean
+	CombineAverage
+	CombineGeometricMean
+	CombineMin
+	CombineMultiply
+	CombineMax
+)
+
+// MaterialPairOverride holds exact values for a specific pair of named materials
+type MaterialPairOverride struct {
+	Restitution     float64
+	StaticFriction  float64
+	DynamicFriction float64
+}
+
+type materialPair struct {
+	a, b string
+}
+
+var materialPairOverrides = map[materialPair]MaterialPairOverride{}
+
+func newMaterialPair(a, b string) materialPair {
+	if a > b {
+		a, b = b, a
+	}
+	return materialPair{a, b}
+}
+
+// SetMaterialPairOverride makes contacts between the two named materials use exact values,
+// regardless of combine modes. The pair is unordered.
+func SetMaterialPairOverride(a, b string, override MaterialPairOverride) {
+	materialPairOverrides[newMaterialPair(a, b)] = override
+}
+
+// RemoveMaterialPairOverride deletes the override for a pair of named materials
+func RemoveMaterialPairOverride(a, b string) {
+	delete(materialPairOverrides, newMaterialPair(a, b))
+}
+
+// GetMaterialPairOverride returns the override for two materials, if both are named and one is set
+func GetMaterialPairOverride(m1, m2 *MaterialData) (MaterialPairOverride, bool) {
+	if m1 == nil || m2 == nil || m1.Name == "" || m2.Name == "" {
+		return MaterialPairOverride{}, false
+	}
+	override, ok := materialPairOverrides[newMaterialPair(m1.Name, m2.Name)]
+	return override, ok
+}
+
+// combineValues mixes two material values with a mode resolveCombineMode has picked, so
+// never CombineDefault, whose meaning depends on the property being mixed
+func combineValues(a, b float64, mode CombineMode) float64 {
+	switch mode {
+	case CombineAverage:
+		return (a + b) / 2
+	case CombineGeometricMean:
+		return math.Sqrt(math.Abs(a * b))
+	case CombineMin:
+		return math.Min(a, b)
+	case CombineMultiply:
+		return a * b
+	case CombineMax:
+		return math.Max(a, b)
+	}
+	return (a + b) / 2
+}
+
+// resolveCombineMode picks the mode with the highest precedence, replacing CombineDefault with fallback
+func resolveCombineMode(m1, m2, fallback CombineMode) CombineMode {
+	if m1 == CombineDefault {
+		m1 = fallback
+	}
+	if m2 == CombineDefault {
+		m2 = fallback
+	}
+	if m1 > m2 {
+		return m1
+	}
+	return m2
+}
+
+// CombineRestitution returns the restitution of a contact between two materials
+func CombineRestitution(m1, m2 *MaterialData) float64 {
+	if override, ok := GetMaterialPairOverride(m1, m2); ok {
+		return override.Restitution
+	}
+	if m1 == nil || m2 == nil {
+		return 0
+	}
+	mode := resolveCombineMode(m1.RestitutionCombine, m2.RestitutionCombine, CombineMin)
+	return combineValues(m1.Restitution, m2.Restitution, mode)
+}
+
+// CombineFriction returns the static and dynamic friction coefficients of a contact between two materials
+func CombineFriction(m1, m2 *MaterialData) (float64, float64) {
+	if override, ok := GetMaterialPairOverride(m1, m2); ok {
+		return override.StaticFriction, override.DynamicFriction
+	}
+	if m1 == nil || m2 == nil {
+		return 0, 0
+	}
+	mode := resolveCombineMode(m1.FrictionCombine, m2.FrictionCombine, CombineGeometricMean)
+	return combineValues(m1.StaticFriction, m2.StaticFriction, mode),
+		combineValues(m1.DynamicFriction, m2.DynamicFriction, mode)
+}
@@ -0,0 +1,84 @@
+package components
+
+import (
+	"math"
+	Vec2 "physengine/helpers/vec2"
+
+	"github.com/yohamta/donburi"
+)
+
+// MotionLimitsData constrains how a body may move. Locks make the body infinitely heavy
+// along the locked axis or against rotation, both when it is integrated and when impulses
+// push on it, so the rest of the solver sees it as fixed there.
+type MotionLimitsData struct {
+	MaxSpeed        float64 // Largest linear speed, 0 for unlimited
+	MaxAngularSpeed float64 // Largest angular speed in rad/s, 0 for unlimited
+	FreezeRotation  bool
+	LockX           bool // Never move along the world X axis
+	LockY           bool // Never move along the world Y axis
+}
+
+var MotionLimits = donburi.NewComponentType[MotionLimitsData]()
+
+// LimitVelocity returns a velocity with the locked axes removed and clamped to MaxSpeed
+func (l *MotionLimitsData) LimitVelocity(v Vec2.Vec2) Vec2.Vec2 {
+	v = l.Mask(v)
+	if speed := v.Magnitude(); l.MaxSpeed > 0 && speed > l.MaxSpeed {
+		v = v.Mult(l.MaxSpeed / speed)
+	}
+	return v
+}
+
+// LimitAngularVelocity returns an angular velocity that is zero if rotation is frozen
+// and clamped to MaxAngularSpeed otherwise
+func (l *MotionLimitsData) LimitAngularVelocity(w float64) float64 {
+	if l.FreezeRotation {
+		return 0
+	}
+	if l.MaxAngularSpeed > 0 && math.Abs(w) > l.MaxAngularSpeed {
+		return math.Copysign(l.MaxAngularSpeed, w)
+	}
+	return w
+}
+
+// Mask returns a vector with its components along the locked axes removed
+func (l *MotionLimitsData) Mask(v Vec2.Vec2) Vec2.Vec2 {
+	if l.LockX {
+		v.X = 0
+	}
+	if l.LockY {
+		v.Y = 0
+	}
+	return v
+}
+
+// MaskLockedAxes returns a velocity change or displacement of a body with the parts along
+// its locked axes removed
+func MaskLockedAxes(entry *donburi.Entry, v Vec2.Vec2) Vec2.Vec2 {
+	if !entry.HasComponent(MotionLimits) {
+		return v
+	}
+	return MotionLimits.Get(entry).Mask(v)
+}
+
+// InverseInertiaOf returns the inverse inertia impulses see on a body, which is zero if
+// the body has no mass or its rotation is frozen
+func InverseInertiaOf(entry *donburi.Entry) float64 {
+	if !entry.HasComponent(MassComponent) {
+		return 0
+	}
+	if entry.HasComponent(MotionLimits) && MotionLimits.Get(entry).FreezeRotation {
+		return 0
+	}
+	return MassComponent.Get(entry).InverseInertia
+}
+
+// InverseMassAlong returns the inverse mass impulses along a unit direction see on a body,
+// which drops to zero for a direction along a locked axis
+func InverseMassAlong(entry *donburi.Entry, dir Vec2.Vec2) float64 {
+	if !entry.HasComponent(MassComponent) {
+		return 0
+	}
+	free := MaskLockedAxes(entry, dir)
+	return MassComponent.Get(entry).InverseMass * Vec2.DotProduct(free, free)
+}
@@ -0,0 +1,27 @@
+package components
+
+import "github.com/yohamta/donburi"
+
+// MotorData drives the rotation of a body through its Torque. By default it spins the body
+// towards Speed; with HoldAngle set, a PID controller turns the body to TargetAngle instead.
+// Either way the torque is clamped to MaxTorque, so the motor can stall against a load.
+type MotorData struct {
+	Enabled   bool
+	Speed     float64 // Target angular velocity in rad/s
+	MaxTorque float64 // 0 is unlimited
+
+	HoldAngle   bool
+	TargetAngle float64 // Radians
+	// PID gains, per unit of inertia so a tuning works for bodies of any size: Kp in 1/s²,
+	// Ki in 1/s³ and Kd in 1/s
+	Kp, Ki, Kd float64
+
+	Integral float64 // Accumulated angle error of the PID controller
+	Applied  float64 // Torque applied in the last step
+}
+
+var Motor = donburi.NewComponentType[MotorData](MotorData{
+	Enabled: true,
+	Kp:      40,
+	Kd:      12,
+})
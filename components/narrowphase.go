@@ -0,0 +1,356 @@
+package components
+
+import (
+	"math"
+	"physengine/helpers"
+	Vec2 "physengine/helpers/vec2"
+)
+
+const narrowphaseEpsilon = 1e-9
+
+// ShapeContact is the result of a narrowphase test between two world shapes
+type ShapeContact struct {
+	Normal      Vec2.Vec2 // Unit vector from the first shape to the second
+	Penetration float64
+	Point       Vec2.Vec2
+}
+
+// CollideShapes tests two world shapes for overlap. Every shape is a convex core
+// (point, segment or polygon) inflated by a radius: when the cores are apart the
+// contact follows their closest points, otherwise SAT on the cores finds the
+// axis of least penetration. One-sided segments ignore shapes behind them and
+// contacts that would push away from their solid side.
+func CollideShapes(a, b WorldShape) (ShapeContact, bool) {
+	contact, ok := collideCores(a, b)
+	if !ok {
+		return ShapeContact{}, false
+	}
+	if a.OneSided && !oneSidedAccepts(a, b.Center, contact.Normal) {
+		return ShapeContact{}, false
+	}
+	if b.OneSided && !oneSidedAccepts(b, a.Center, contact.Normal.Mult(-1)) {
+		return ShapeContact{}, false
+	}
+	if a.HasPrev || a.HasNext {
+		if contact, ok = smoothChainContact(a, b, contact); !ok {
+			return ShapeContact{}, false
+		}
+	}
+	if b.HasPrev || b.HasNext {
+		contact.Normal = contact.Normal.Mult(-1)
+		if contact, ok = smoothChainContact(b, a, contact); !ok {
+			return ShapeContact{}, false
+		}
+		contact.Normal = contact.Normal.Mult(-1)
+	}
+	return contact, true
+}
+
+// smoothChainContact uses the ghost vertices of a chain segment to drop contacts that
+// would snag on an inner vertex. contact.Normal points from the segment to other.
+// At a convex corner only normals between the two face normals belong to this segment;
+// at a flat or concave corner the neighbour covers the vertex, so the face normal is used.
+// A contact on a convex vertex is kept by the segment that ends there.
+func smoothChainContact(segment, other WorldShape, contact ShapeContact) (ShapeContact, bool) {
+	const faceTolerance = 1e-3
+	n := segment.Normal
+	if Vec2.DotProduct(contact.Normal, n) >= 1-faceTolerance {
+		return contact, true
+	}
+
+	v0, v1 := segment.Vertices[0], segment.Vertices[1]
+	edge := v1.Add(v0.Mult(-1))
+	var adjacent Vec2.Vec2
+	var turn float64
+	atEnd := Vec2.DotProduct(contact.Normal, edge) > 0
+	if atEnd {
+		if !segment.HasNext {
+			return contact, true
+		}
+		adjacent = segment.Next.Add(v1.Mult(-1))
+		turn = Vec2.CrossProductVecVec(edge, adjacent)
+	} else {
+		if !segment.HasPrev {
+			return contact, true
+		}
+		adjacent = v0.Add(segment.Prev.Mult(-1))
+		turn = Vec2.CrossProductVecVec(adjacent, edge)
+	}
+
+	if turn < -narrowphaseEpsilon {
+		// Both segments see the same vertex contact, the one ending at the vertex keeps it
+		if !atEnd {
+			return ShapeContact{}, false
+		}
+		adjacentNormal := Vec2.Vec2{X: -adjacent.Y, Y: adjacent.X}.Normalized()
+		span := Vec2.CrossProductVecVec(n, adjacentNormal)
+		if Vec2.CrossProductVecVec(n, contact.Normal)*span >= 0 && Vec2.CrossProductVecVec(contact.Normal, adjacentNormal)*span >= 0 {
+			return contact, true
+		}
+		return ShapeContact{}, false
+	}
+
+	// Flat or concave: only a face contact, and only while other overlaps this segment's span
+	dir := edge.Normalized()
+	otherMin, otherMax := other.Project(dir)
+	if otherMax <= Vec2.DotProduct(v0, dir) || otherMin >= Vec2.DotProduct(v1, dir) {
+		return ShapeContact{}, false
+	}
+	otherBottom, _ := other.Project(n)
+	penetration := Vec2.DotProduct(v0, n) + segment.Radius - otherBottom
+	if penetration <= 0 {
+		return ShapeContact{}, false
+	}
+	return ShapeContact{Normal: n, Penetration: penetration, Point: contact.Point}, true
+}
+
+// oneSidedAccepts reports whether a contact pushing other along normal comes from the
+// solid side of a one-sided segment
+func oneSidedAccepts(segment WorldShape, other Vec2.Vec2, normal Vec2.Vec2) bool {
+	if Vec2.DotProduct(other.Add(segment.Vertices[0].Mult(-1)), segment.Normal) < 0 {
+		return false
+	}
+	return Vec2.DotProduct(normal, segment.Normal) > 0
+}
+
+func collideCores(a, b WorldShape) (ShapeContact, bool) {
+	radii := a.Radius + b.Radius
+
+	if !coresOverlap(a.Vertices, b.Vertices) {
+		pa, pb := closestPointsBetweenCores(a.Vertices, b.Vertices)
+		delta := pb.Add(pa.Mult(-1))
+		dist := delta.Magnitude()
+		if dist >= radii {
+			return ShapeContact{}, false
+		}
+		normal := delta.Mult(1 / dist)
+		// Contact point halfway between the two surfaces
+		surfaceA := pa.Add(normal.Mult(a.Radius))
+		surfaceB := pb.Add(normal.Mult(-b.Radius))
+		return ShapeContact{
+			Normal:      normal,
+			Penetration: radii - dist,
+			Point:       surfaceA.Add(surfaceB).Mult(0.5),
+		}, true
+	}
+
+	normal, overlap, ok := coreSAT(a.Vertices, b.Vertices)
+	if !ok {
+		// Coincident points (e.g. concentric circles) have no separating axis to test
+		normal = Vec2.Vec2{X: 1, Y: 0}
+		overlap = 0
+	}
+	if Vec2.DotProduct(b.Center.Add(a.Center.Mult(-1)), normal) < 0 {
+		normal.Invert()
+	}
+	return ShapeContact{
+		Normal:      normal,
+		Penetration: overlap + radii,
+		Point:       overlapContactPoint(a, b, normal),
+	}, true
+}
+
+// coresOverlap reports whether two convex cores intersect
+func coresOverlap(a, b []Vec2.Vec2) bool {
+	pa, pb := closestPointsBetweenCores(a, b)
+	if Vec2.Distance(pa, pb) < narrowphaseEpsilon {
+		return true
+	}
+	if len(a) >= 3 && pointInConvexPolygon(b[0], a) {
+		return true
+	}
+	if len(b) >= 3 && pointInConvexPolygon(a[0], b) {
+		return true
+	}
+	return false
+}
+
+// coreSAT returns the axis of least overlap between two intersecting cores
+func coreSAT(a, b []Vec2.Vec2) (Vec2.Vec2, float64, bool) {
+	axes := append(edgeNormals(a), edgeNormals(b)...)
+	if len(axes) == 0 {
+		return Vec2.Vec2{}, 0, false
+	}
+	minOverlap := math.Inf(1)
+	var best Vec2.Vec2
+	for _, axis := range axes {
+		minA, maxA := projectPoints(a, axis)
+		minB, maxB := projectPoints(b, axis)
+		overlap := math.Min(maxA-minB, maxB-minA)
+		if overlap < minOverlap {
+			minOverlap = overlap
+			best = axis
+		}
+	}
+	return best, minOverlap, true
+}
+
+// overlapContactPoint averages the core vertices of each shape that lie inside the
+// other; for crossing edges it falls back to the midpoint of the closest points
+func overlapContactPoint(a, b WorldShape, normal Vec2.Vec2) Vec2.Vec2 {
+	var sum Vec2.Vec2
+	count := 0
+	for _, v := range b.Vertices {
+		if a.ContainsPoint(v) {
+			sum.AddUpdate(v.Add(normal.Mult(-b.Radius)))
+			count++
+		}
+	}
+	for _, v := range a.Vertices {
+		if b.ContainsPoint(v) {
+			sum.AddUpdate(v.Add(normal.Mult(a.Radius)))
+			count++
+		}
+	}
+	if count > 0 {
+		return sum.Mult(1 / float64(count))
+	}
+	pa, pb := closestPointsBetweenCores(a.Vertices, b.Vertices)
+	return pa.Add(pb).Mult(0.5)
+}
+
+// edgeNormals returns the unit normals of a core's edges; a segment has one, a point none
+func edgeNormals(core []Vec2.Vec2) []Vec2.Vec2 {
+	if len(core) < 2 {
+		return nil
+	}
+	count := len(core)
+	if count == 2 {
+		count = 1
+	}
+	normals := make([]Vec2.Vec2, 0, count)
+	for i := 0; i < count; i++ {
+		next := core[(i+1)%len(core)]
+		edge := next.Add(core[i].Mult(-1))
+		normal := Vec2.Vec2{X: edge.Y, Y: -edge.X}.Normalized()
+		if normal.SquareMagnitude() > 0 {
+			normals = append(normals, normal)
+		}
+	}
+	return normals
+}
+
+// coreEdges returns the edges of a core as point pairs; a point is a degenerate edge
+func coreEdges(core []Vec2.Vec2) [][2]Vec2.Vec2 {
+	switch len(core) {
+	case 0:
+		return nil
+	case 1:
+		return [][2]Vec2.Vec2{{core[0], core[0]}}
+	case 2:
+		return [][2]Vec2.Vec2{{core[0], core[1]}}
+	}
+	edges := make([][2]Vec2.Vec2, len(core))
+	for i := range core {
+		edges[i] = [2]Vec2.Vec2{core[i], core[(i+1)%len(core)]}
+	}
+	return edges
+}
+
+// closestPointsBetweenCores returns the closest points on the boundaries of two cores
+func closestPointsBetweenCores(a, b []Vec2.Vec2) (Vec2.Vec2, Vec2.Vec2) {
+	best := math.Inf(1)
+	var bestA, bestB Vec2.Vec2
+	for _, ea := range coreEdges(a) {
+		for _, eb := range coreEdges(b) {
+			pa, pb := ClosestPointsSegmentSegment(ea[0], ea[1], eb[0], eb[1])
+			d := Vec2.Distance(pa, pb)
+			if d < best {
+				best = d
+				bestA, bestB = pa, pb
+			}
+		}
+	}
+	return bestA, bestB
+}
+
+// distanceToCore returns the distance from a point to the boundary of a core
+func distanceToCore(p Vec2.Vec2, core []Vec2.Vec2) float64 {
+	best := math.Inf(1)
+	for _, e := range coreEdges(core) {
+		best = math.Min(best, Vec2.Distance(p, ClosestPointOnSegment(p, e[0], e[1])))
+	}
+	return best
+}
+
+// ClosestPointOnSegment returns the point of segment ab closest to p
+func ClosestPointOnSegment(p, a, b Vec2.Vec2) Vec2.Vec2 {
+	ab := b.Add(a.Mult(-1))
+	lenSq := ab.SquareMagnitude()
+	if lenSq < narrowphaseEpsilon {
+		return a
+	}
+	t := Vec2.DotProduct(p.Add(a.Mult(-1)), ab) / lenSq
+	return a.Add(ab.Mult(helpers.Clamp(t, 0, 1)))
+}
+
+// ClosestPointsSegmentSegment returns the closest points between segments p1q1 and p2q2
+// (Ericson, Real-Time Collision Detection 5.1.9)
+func ClosestPointsSegmentSegment(p1, q1, p2, q2 Vec2.Vec2) (Vec2.Vec2, Vec2.Vec2) {
+	d1 := q1.Add(p1.Mult(-1))
+	d2 := q2.Add(p2.Mult(-1))
+	r := p1.Add(p2.Mult(-1))
+	a := d1.SquareMagnitude()
+	e := d2.SquareMagnitude()
+	f := Vec2.DotProduct(d2, r)
+
+	var s, t float64
+	if a <= narrowphaseEpsilon && e <= narrowphaseEpsilon {
+		return p1, p2
+	}
+	if a <= narrowphaseEpsilon {
+		t = helpers.Clamp(f/e, 0, 1)
+	} else {
+		c := Vec2.DotProduct(d1, r)
+		if e <= narrowphaseEpsilon {
+			s = helpers.Clamp(-c/a, 0, 1)
+		} else {
+			b := Vec2.DotProduct(d1, d2)
+			denom := a*e - b*b
+			if denom != 0 {
+				s = helpers.Clamp((b*f-c*e)/denom, 0, 1)
+			}
+			t = (b*s + f) / e
+			if t < 0 {
+				t = 0
+				s = helpers.Clamp(-c/a, 0, 1)
+			} else if t > 1 {
+				t = 1
+				s = helpers.Clamp((b-c)/a, 0, 1)
+			}
+		}
+	}
+
+	return p1.Add(d1.Mult(s)), p2.Add(d2.Mult(t))
+}
+
+// pointInConvexPolygon reports whether p is inside a convex polygon of either winding
+func pointInConvexPolygon(p Vec2.Vec2, poly []Vec2.Vec2) bool {
+	sign := 0.0
+	for i := range poly {
+		a := poly[i]
+		b := poly[(i+1)%len(poly)]
+		cross := Vec2.CrossProductVecVec(b.Add(a.Mult(-1)), p.Add(a.Mult(-1)))
+		if math.Abs(cross) < narrowphaseEpsilon {
+			continue
+		}
+		if sign == 0 {
+			sign = cross
+		} else if sign*cross < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// projectPoints returns the extent of a point set along an axis
+func projectPoints(points []Vec2.Vec2, axis Vec2.Vec2) (float64, float64) {
+	min := math.Inf(1)
+	max := math.Inf(-1)
+	for _, p := range points {
+		proj := Vec2.DotProduct(p, axis)
+		min = math.Min(min, proj)
+		max = math.Max(max, proj)
+	}
+	return min, max
+}
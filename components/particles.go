@@ -0,0 +1,153 @@
+package components
+
+import (
+	"image/color"
+	"math"
+	"math/rand"
+	Vec2 "physengine/helpers/vec2"
+
+	"github.com/yohamta/donburi"
+)
+
+// Particle is a lightweight point owned by an emitter. Particles are plain values in the
+// emitter's slice rather than entities, so thousands of them stay cheap.
+type Particle struct {
+	Pos      Vec2.Vec2
+	Vel      Vec2.Vec2
+	Age      float64
+	Lifetime float64
+}
+
+// ColorKey is one point of a colour curve over a particle's normalised age
+type ColorKey struct {
+	T     float64 // 0 at birth, 1 at death
+	Color color.RGBA
+}
+
+// SizeKey is one point of a size curve over a particle's normalised age
+type SizeKey struct {
+	T    float64
+	Size float64 // Edge length in world units
+}
+
+// ParticleEmitterData spawns and owns particles. Emitters with a Transform emit from
+// the entity position; any emitter can also be fed bursts at arbitrary points.
+type ParticleEmitterData struct {
+	Emitting bool
+	Rate     float64   // Particles per second while emitting
+	Offset   Vec2.Vec2 // Emission point relative to the entity, rotated with it
+
+	Lifetime       float64 // Seconds
+	LifetimeJitter float64 // Random extra lifetime, up to this many seconds
+	Speed          float64
+	SpeedJitter    float64
+	Direction      float64 // Emission angle in radians relative to the entity rotation
+	Spread         float64 // Particles leave within ±Spread/2 of Direction
+
+	Colors []ColorKey // Sorted by T; empty means white
+	Sizes  []SizeKey  // Sorted by T; empty means 2
+
+	GravityScale float64 // Fraction of the world gravity applied to the particles
+	Drag         float64 // Exponential velocity decay rate in 1/s
+
+	Collide     bool    // Bounce off colliders; particles are treated as points
+	Restitution float64 // Bounciness of particle collisions
+	Friction    float64 // Fraction of the sliding speed lost per bounce
+
+	MaxParticles int // Oldest particles are not replaced; new ones are dropped at the cap
+
+	Particles []Particle
+	SpawnDebt float64 // Fraction of a particle carried over to the next step
+}
+
+var ParticleEmitter = donburi.NewComponentType[ParticleEmitterData](ParticleEmitterData{
+	Lifetime:     1,
+	Speed:        100,
+	Spread:       2 * math.Pi,
+	GravityScale: 1,
+	MaxParticles: 2000,
+})
+
+// ContactSparksData makes a particle emitter spray sparks from collision contacts. The
+// number of sparks grows with the impulse of the contact.
+type ContactSparksData struct {
+	MinImpulse        float64 // Weaker contacts make no sparks
+	SparksPerImpulse  float64
+	MaxSparksPerEvent int
+}
+
+var ContactSparks = donburi.NewComponentType[ContactSparksData](ContactSparksData{
+	MinImpulse:        100,
+	SparksPerImpulse:  0.05,
+	MaxSparksPerEvent: 30,
+})
+
+// Emit adds up to count particles at pos, heading within ±spread/2 of angle, with speeds
+// scaled by speedScale. It returns how many were added before the cap was reached.
+func (p *ParticleEmitterData) Emit(pos Vec2.Vec2, angle, spread, speedScale float64, count int) int {
+	added := 0
+	for ; added < count; added++ {
+		if p.MaxParticles > 0 && len(p.Particles) >= p.MaxParticles {
+			break
+		}
+		a := angle + (rand.Float64()-0.5)*spread
+		speed := (p.Speed + rand.Float64()*p.SpeedJitter) * speedScale
+		p.Particles = append(p.Particles, Particle{
+			Pos:      pos,
+			Vel:      Vec2.Vec2{X: math.Cos(a) * speed, Y: math.Sin(a) * speed},
+			Lifetime: p.Lifetime + rand.Float64()*p.LifetimeJitter,
+		})
+	}
+	return added
+}
+
+// ColorAt returns the colour of a particle at normalised age t
+func (p *ParticleEmitterData) ColorAt(t float64) color.RGBA {
+	keys := p.Colors
+	if len(keys) == 0 {
+		return color.RGBA{R: 255, G: 255, B: 255, A: 255}
+	}
+	if t <= keys[0].T {
+		return keys[0].Color
+	}
+	for i := 1; i < len(keys); i++ {
+		if t <= keys[i].T {
+			a, b := keys[i-1], keys[i]
+			f := (t - a.T) / (b.T - a.T)
+			lerp := func(x, y uint8) uint8 { return uint8(float64(x) + f*(float64(y)-float64(x)) + 0.5) }
+			return color.RGBA{R: lerp(a.Color.R, b.Color.R), G: lerp(a.Color.G, b.Color.G), B: lerp(a.Color.B, b.Color.B), A: lerp(a.Color.A, b.Color.A)}
+		}
+	}
+	return keys[len(keys)-1].Color
+}
+
+// SizeAt returns the size of a particle at normalised age t
+func (p *ParticleEmitterData) SizeAt(t float64) float64 {
+	keys := p.Sizes
+	if len(keys) == 0 {
+		return 2
+	}
+	if t <= keys[0].T {
+		return keys[0].Size
+	}
+	for i := 1; i < len(keys); i++ {
+		if t <= keys[i].T {
+			a, b := keys[i-1], keys[i]
+			return a.Size + (t-a.T)/(b.T-a.T)*(b.Size-a.Size)
+		}
+	}
+	return keys[len(keys)-1].Size
+}
+
+// Bounds returns the world-space box around all particles
+func (p *ParticleEmitterData) Bounds() (Vec2.Vec2, Vec2.Vec2) {
+	min := Vec2.Vec2{X: math.Inf(1), Y: math.Inf(1)}
+	max := Vec2.Vec2{X: math.Inf(-1), Y: math.Inf(-1)}
+	for _, particle := range p.Particles {
+		min.X = math.Min(min.X, particle.Pos.X)
+		min.Y = math.Min(min.Y, particle.Pos.Y)
+		max.X = math.Max(max.X, particle.Pos.X)
+		max.Y = math.Max(max.Y, particle.Pos.Y)
+	}
+	return min, max
+}
@@ -0,0 +1,17 @@
+package components
+
+import (
+	"time"
+
+	"github.com/yohamta/donburi"
+)
+
+// PhysicsClockData tracks the physics steps run by systems.FixedStep
+type PhysicsClockData struct {
+	Step        time.Duration // Length of the step being simulated
+	Accumulator time.Duration // Frame time not simulated yet
+	Steps       int           // Steps run in the last frame
+	Dropped     time.Duration // Time dropped because a frame needed more than MaxSubsteps steps
+}
+
+var PhysicsClock = donburi.NewComponentType[PhysicsClockData]()
@@ -0,0 +1,38 @@
+package components
+
+import (
+	Vec2 "physengine/helpers/vec2"
+	"time"
+
+	"github.com/yohamta/donburi"
+)
+
+// PhysicsWorldData holds world-wide simulation settings
+type PhysicsWorldData struct {
+	DefaultLinearDamping  float64 // Used by bodies without a Damping component
+	DefaultAngularDamping float64
+	AirDensity            float64   // ρ for AirDrag, mass per square world unit
+	Gravity               Vec2.Vec2 // Acceleration applied to every dynamic body, zero for a top-down sandbox
+	Integrator            Integrator
+
+	FixedStep   time.Duration // Length of every physics step, 0 to step once per frame by the frame time
+	MaxSubsteps int           // Most fixed steps run in one frame; time beyond them is dropped
+
+	GravitationalConstant float64 // G for Attractor bodies, 0 turns attractors off
+	BarnesHutTheta        float64 // Opening angle of the attractor quadtree, 0 for exact sums
+
+	SleepTime         float64 // Seconds a body must stay below the sleep speeds to sleep, 0 never sleeps
+	SleepSpeed        float64 // Linear speed under which a body counts as at rest
+	SleepAngularSpeed float64 // Angular speed in rad/s under which a body counts as at rest
+}
+
+var PhysicsWorld = donburi.NewComponentType[PhysicsWorldData]()
+
+// GetPhysicsWorld returns the world settings, or nil if the world has none
+func GetPhysicsWorld(w donburi.World) *PhysicsWorldData {
+	entry, ok := PhysicsWorld.First(w)
+	if !ok {
+		return nil
+	}
+	return PhysicsWorld.Get(entry)
+}
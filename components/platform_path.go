@@ -0,0 +1,16 @@
+package components
+
+import (
+	Vec2 "physengine/helpers/vec2"
+
+	"github.com/yohamta/donburi"
+)
+
+// PlatformPathData moves a kinematic body back and forth between two world points
+type PlatformPathData struct {
+	A, B     Vec2.Vec2
+	Speed    float64
+	TowardsA bool
+}
+
+var PlatformPath = donburi.NewComponentType[PlatformPathData]()
@@ -0,0 +1,18 @@
+package components
+
+import (
+	Vec2 "physengine/helpers/vec2"
+
+	"github.com/yohamta/donburi"
+)
+
+// RevoluteJointData pins an anchor on body B to an anchor on body A, leaving both bodies
+// free to rotate about the shared point
+type RevoluteJointData struct {
+	LocalAnchorA Vec2.Vec2
+	LocalAnchorB Vec2.Vec2
+
+	Impulse Vec2.Vec2 // Impulse at the pin in the last step, reused to warm start the next one
+}
+
+var RevoluteJoint = donburi.NewComponentType[RevoluteJointData]()
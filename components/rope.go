@@ -0,0 +1,61 @@
+package components
+
+import (
+	"image/color"
+	Vec2 "physengine/helpers/vec2"
+
+	"github.com/yohamta/donburi"
+)
+
+// RopeData groups the link bodies and joints of a rope so it can be drawn as one line.
+// Joints[i] joins the body before Links[i] to it, and the last joint joins the last link
+// to the end anchor. Torn joints are removed from the world and leave gaps.
+type RopeData struct {
+	Links  []*donburi.Entry
+	Joints []*donburi.Entry
+	// Rope joint straight from end to end at the full length. A long chain of light links
+	// cannot hold a heavy load on its own, so this keeps the ends within reach of each
+	// other until the rope tears.
+	Limit     *donburi.Entry
+	Thickness float64
+	Color     color.RGBA
+}
+
+var Rope = donburi.NewComponentType[RopeData]()
+
+// Torn reports whether any joint of the rope has broken
+func (r *RopeData) Torn() bool {
+	for _, joint := range r.Joints {
+		if !joint.Valid() {
+			return true
+		}
+	}
+	return false
+}
+
+// Polylines returns the rope as world-space polylines, split wherever it has torn
+func (r *RopeData) Polylines() [][]Vec2.Vec2 {
+	var lines [][]Vec2.Vec2
+	var current []Vec2.Vec2
+	flush := func() {
+		if len(current) >= 2 {
+			lines = append(lines, current)
+		}
+		current = nil
+	}
+	for i, joint := range r.Joints {
+		if a, b, ok := JointAnchors(joint); ok {
+			current = append(current, a, b)
+		} else {
+			flush()
+		}
+		if i < len(r.Links) && r.Links[i].Valid() {
+			// A capsule link adds both ends of its core, a bead its centre
+			if shapes := WorldShapes(r.Links[i]); len(shapes) > 0 {
+				current = append(current, shapes[0].Vertices...)
+			}
+		}
+	}
+	flush()
+	return lines
+}
@@ -0,0 +1,23 @@
+package components
+
+import (
+	Vec2 "physengine/helpers/vec2"
+
+	"github.com/yohamta/donburi"
+)
+
+// SegmentColliderData is a line from A to B in body space, typically on a static body for
+// ramps and ground. One-sided segments only collide with shapes on the left of A->B, so
+// with A left of B the solid side faces up and bodies can jump through from below.
+type SegmentColliderData struct {
+	A, B     Vec2.Vec2
+	Radius   float64 // Thickness, 0 for an infinitely thin line
+	OneSided bool
+}
+
+var SegmentCollider = donburi.NewComponentType[SegmentColliderData]()
+
+// Shape returns the segment as a body-space collision shape
+func (s *SegmentColliderData) Shape() Shape {
+	return Shape{Kind: ShapeSegment, A: s.A, B: s.B, Radius: s.Radius, OneSided: s.OneSided}
+}
@@ -0,0 +1,307 @@
+package components
+
+import (
+	"math"
+	Vec2 "physengine/helpers/vec2"
+
+	"github.com/yohamta/donburi"
+	"github.com/yohamta/donburi/filter"
+)
+
+type ShapeKind int
+
+const (
+	ShapeCircle ShapeKind = iota
+	ShapeBox
+	ShapeCapsule
+	ShapeSegment
+	ShapePolygon
+)
+
+// Shape is a collision primitive in the local space of its body
+type Shape struct {
+	Kind        ShapeKind
+	Offset      Vec2.Vec2 // Centre of the shape in body space
+	Rot         float64   // Rotation of the shape in body space
+	Radius      float64   // ShapeCircle
+	HalfExtents Vec2.Vec2 // ShapeBox
+	HalfLength  float64   // ShapeCapsule, half the distance between the cap centres along local Y
+	A, B        Vec2.Vec2 // ShapeSegment end points in body space
+	OneSided    bool      // ShapeSegment, only collides with shapes on the left of A->B
+	Prev, Next  Vec2.Vec2 // ShapeSegment ghost vertices before A and after B in a chain
+	HasPrev     bool
+	HasNext     bool
+	Vertices    []Vec2.Vec2 // ShapePolygon, convex and counter-clockwise, placed by Offset and Rot
+	Density     float64     // Mass per square unit for compound mass properties, 0 uses the default
+}
+
+// CompoundColliderData attaches several shapes to one body
+type CompoundColliderData struct {
+	Shapes []Shape
+}
+
+var CompoundCollider = donburi.NewComponentType[CompoundColliderData]()
+
+// WorldShape is a shape transformed to world space, described as a convex core
+// (one point for circles, two for capsules and segments, polygon corners for boxes)
+// inflated by Radius
+type WorldShape struct {
+	Kind     ShapeKind
+	Center   Vec2.Vec2
+	Vertices []Vec2.Vec2 // Counter-clockwise
+	Radius   float64
+	OneSided bool
+	Normal   Vec2.Vec2 // Solid side of a one-sided segment
+
+	// Ghost vertices of chain segments
+	Prev, Next       Vec2.Vec2
+	HasPrev, HasNext bool
+}
+
+// ColliderFilter matches every entity that has at least one collision shape
+func ColliderFilter() filter.LayoutFilter {
+	return filter.And(
+		filter.Contains(Transform),
+		filter.Or(
+			filter.Contains(CircleCollider),
+			filter.Contains(AABB_Component),
+			filter.Contains(CapsuleCollider),
+			filter.Contains(SegmentCollider),
+			filter.Contains(ChainCollider),
+			filter.Contains(TilemapCollider),
+			filter.Contains(CompoundCollider),
+		),
+	)
+}
+
+// HasCollider reports whether an entity has any collision shape
+func HasCollider(entry *donburi.Entry) bool {
+	return entry.HasComponent(CircleCollider) || entry.HasComponent(AABB_Component) || entry.HasComponent(CapsuleCollider) ||
+		entry.HasComponent(SegmentCollider) || entry.HasComponent(ChainCollider) || entry.HasComponent(TilemapCollider) ||
+		entry.HasComponent(CompoundCollider)
+}
+
+// BodyShapes returns all collision shapes of an entity in body space
+func BodyShapes(entry *donburi.Entry) []Shape {
+	var shapes []Shape
+	if entry.HasComponent(CircleCollider) {
+		shapes = append(shapes, CircleCollider.Get(entry).Shape())
+	}
+	if entry.HasComponent(AABB_Component) {
+		shapes = append(shapes, AABB_Component.Get(entry).Shape())
+	}
+	if entry.HasComponent(CapsuleCollider) {
+		shapes = append(shapes, CapsuleCollider.Get(entry).Shape())
+	}
+	if entry.HasComponent(SegmentCollider) {
+		shapes = append(shapes, SegmentCollider.Get(entry).Shape())
+	}
+	if entry.HasComponent(ChainCollider) {
+		shapes = append(shapes, ChainCollider.Get(entry).Shapes()...)
+	}
+	if entry.HasComponent(TilemapCollider) {
+		shapes = append(shapes, TilemapCollider.Get(entry).Shapes()...)
+	}
+	if entry.HasComponent(CompoundCollider) {
+		shapes = append(shapes, CompoundCollider.Get(entry).Shapes...)
+	}
+	return shapes
+}
+
+// WorldShapes returns all collision shapes of an entity in world space
+func WorldShapes(entry *donburi.Entry) []WorldShape {
+	if !entry.HasComponent(Transform) {
+		return nil
+	}
+	tr := Transform.Get(entry)
+	shapes := BodyShapes(entry)
+	world := make([]WorldShape, 0, len(shapes))
+	for _, s := range shapes {
+		world = append(world, s.ToWorld(tr.Pos, tr.Rot))
+	}
+	return world
+}
+
+// ToWorld places a body-space shape at a body position and rotation
+func (s Shape) ToWorld(pos Vec2.Vec2, rot float64) WorldShape {
+	center := pos.Add(RotatePoint(s.Offset, rot))
+	ws := WorldShape{Kind: s.Kind, Center: center}
+	switch s.Kind {
+	case ShapeCircle:
+		ws.Vertices = []Vec2.Vec2{center}
+		ws.Radius = s.Radius
+	case ShapeBox:
+		hx, hy := s.HalfExtents.X, s.HalfExtents.Y
+		local := []Vec2.Vec2{{X: -hx, Y: -hy}, {X: hx, Y: -hy}, {X: hx, Y: hy}, {X: -hx, Y: hy}}
+		ws.Vertices = make([]Vec2.Vec2, len(local))
+		for i, v := range local {
+			ws.Vertices[i] = center.Add(RotatePoint(v, rot+s.Rot))
+		}
+	case ShapePolygon:
+		ws.Vertices = make([]Vec2.Vec2, len(s.Vertices))
+		for i, v := range s.Vertices {
+			ws.Vertices[i] = center.Add(RotatePoint(v, rot+s.Rot))
+		}
+		ws.Center = pos.Add(RotatePoint(s.Centroid(), rot))
+	case ShapeCapsule:
+		axis := RotatePoint(Vec2.Vec2{X: 0, Y: s.HalfLength}, rot+s.Rot)
+		ws.Vertices = []Vec2.Vec2{center.Add(axis.Mult(-1)), center.Add(axis)}
+		ws.Radius = s.Radius
+	case ShapeSegment:
+		a := pos.Add(RotatePoint(s.A, rot))
+		b := pos.Add(RotatePoint(s.B, rot))
+		ws.Center = a.Add(b).Mult(0.5)
+		ws.Vertices = []Vec2.Vec2{a, b}
+		ws.Radius = s.Radius
+		ws.OneSided = s.OneSided
+		ws.HasPrev, ws.HasNext = s.HasPrev, s.HasNext
+		if s.HasPrev {
+			ws.Prev = pos.Add(RotatePoint(s.Prev, rot))
+		}
+		if s.HasNext {
+			ws.Next = pos.Add(RotatePoint(s.Next, rot))
+		}
+		edge := b.Add(a.Mult(-1))
+		ws.Normal = Vec2.Vec2{X: -edge.Y, Y: edge.X}.Normalized()
+	}
+	return ws
+}
+
+// Bounds returns the world-space axis-aligned bounding box of the shape
+func (ws WorldShape) Bounds() (Vec2.Vec2, Vec2.Vec2) {
+	min, max, _ := pointsBounds(ws.Vertices)
+	min = Vec2.Vec2{X: min.X - ws.Radius, Y: min.Y - ws.Radius}
+	max = Vec2.Vec2{X: max.X + ws.Radius, Y: max.Y + ws.Radius}
+	return min, max
+}
+
+// Project returns the extent of the shape along a unit axis
+func (ws WorldShape) Project(axis Vec2.Vec2) (float64, float64) {
+	min, max := projectPoints(ws.Vertices, axis)
+	return min - ws.Radius, max + ws.Radius
+}
+
+// ContainsPoint reports whether a world point lies inside the shape
+func (ws WorldShape) ContainsPoint(p Vec2.Vec2) bool {
+	if len(ws.Vertices) >= 3 && pointInConvexPolygon(p, ws.Vertices) {
+		return true
+	}
+	return distanceToCore(p, ws.Vertices) <= ws.Radius
+}
+
+// ClosestPoint returns the point on the surface of the shape nearest to p, or p itself when
+// it lies inside the shape
+func (ws WorldShape) ClosestPoint(p Vec2.Vec2) Vec2.Vec2 {
+	if ws.ContainsPoint(p) {
+		return p
+	}
+	best := math.Inf(1)
+	var core Vec2.Vec2
+	for _, e := range coreEdges(ws.Vertices) {
+		q := ClosestPointOnSegment(p, e[0], e[1])
+		if d := Vec2.Distance(p, q); d < best {
+			best, core = d, q
+		}
+	}
+	return core.Add(p.Add(core.Mult(-1)).Mult(ws.Radius / best))
+}
+
+// Area returns the area of a body-space shape
+func (s Shape) Area() float64 {
+	switch s.Kind {
+	case ShapeCircle:
+		return math.Pi * s.Radius * s.Radius
+	case ShapeBox:
+		return 4 * s.HalfExtents.X * s.HalfExtents.Y
+	case ShapeCapsule, ShapeSegment:
+		return 2*s.Radius*s.coreLength() + math.Pi*s.Radius*s.Radius
+	case ShapePolygon:
+		return math.Abs(signedArea(s.Vertices))
+	}
+	return 0
+}
+
+// coreLength returns the distance between the cap centres of a capsule or segment
+func (s Shape) coreLength() float64 {
+	if s.Kind == ShapeSegment {
+		return Vec2.Distance(s.A, s.B)
+	}
+	return 2 * s.HalfLength
+}
+
+// UnitInertia returns the moment of inertia per unit mass about the shape's own centroid
+func (s Shape) UnitInertia() float64 {
+	switch s.Kind {
+	case ShapeCircle:
+		return s.Radius * s.Radius * 0.5 // I = 0.5 * m * r^2 for a circle
+	case ShapeBox:
+		w, h := 2*s.HalfExtents.X, 2*s.HalfExtents.Y
+		return (w*w + h*h) / 12 // I = m(w² + h²) / 12 for a box
+	case ShapeCapsule, ShapeSegment:
+		return capsuleUnitInertia(s.Radius, s.coreLength())
+	case ShapePolygon:
+		return polygonUnitInertia(s.Vertices)
+	}
+	return 0
+}
+
+// capsuleUnitInertia sums a w=2r, h=l box and two half discs moved out to the
+// ends of the box, per unit mass. A zero radius gives a thin rod, l²/12.
+func capsuleUnitInertia(r, l float64) float64 {
+	if r <= 0 {
+		return l * l / 12
+	}
+	boxMass := 2 * r * l
+	capMass := math.Pi * r * r // Both half discs together
+	boxInertia := boxMass * (4*r*r + l*l) / 12
+	// Half disc about its own centroid is m(r²/2 - d²), d = 4r/3π; shift it to l/2 + d
+	d := 4 * r / (3 * math.Pi)
+	capInertia := capMass * (r*r/2 - d*d + (l/2+d)*(l/2+d))
+	return (boxInertia + capInertia) / (boxMass + capMass)
+}
+
+// Centroid returns the centre of mass of the shape in body space
+func (s Shape) Centroid() Vec2.Vec2 {
+	switch s.Kind {
+	case ShapeSegment:
+		return s.A.Add(s.B).Mult(0.5)
+	case ShapePolygon:
+		return s.Offset.Add(RotatePoint(polygonCentroid(s.Vertices), s.Rot))
+	}
+	return s.Offset
+}
+
+// polygonCentroid returns the area centroid of a simple polygon
+func polygonCentroid(points []Vec2.Vec2) Vec2.Vec2 {
+	area := signedArea(points)
+	if math.Abs(area) < narrowphaseEpsilon {
+		min, max, _ := pointsBounds(points)
+		return min.Add(max).Mult(0.5)
+	}
+	var c Vec2.Vec2
+	for i := range points {
+		a := points[i]
+		b := points[(i+1)%len(points)]
+		cross := Vec2.CrossProductVecVec(a, b)
+		c.AddUpdate(a.Add(b).Mult(cross))
+	}
+	return c.Mult(1 / (6 * area))
+}
+
+// polygonUnitInertia returns the moment of inertia per unit mass of a polygon about its centroid
+func polygonUnitInertia(points []Vec2.Vec2) float64 {
+	area := signedArea(points)
+	if math.Abs(area) < narrowphaseEpsilon {
+		return 0
+	}
+	// Inertia about the origin from the triangle fan, then moved to the centroid
+	sum := 0.0
+	for i := range points {
+		a := points[i]
+		b := points[(i+1)%len(points)]
+		cross := Vec2.CrossProductVecVec(a, b)
+		sum += cross * (Vec2.DotProduct(a, a) + Vec2.DotProduct(a, b) + Vec2.DotProduct(b, b))
+	}
+	centroid := polygonCentroid(points)
+	return sum/(12*area) - centroid.SquareMagnitude()
+}
@@ -0,0 +1,96 @@
+package components
+
+import (
+	Vec2 "physengine/helpers/vec2"
+)
+
+// CastTolerance is how close a swept shape has to get to count as touching
+const CastTolerance = 0.01
+
+// ShapeDistance returns the gap between two shapes, negative when they overlap, and the
+// unit normal pointing from a to b
+func ShapeDistance(a, b WorldShape) (float64, Vec2.Vec2) {
+	if coresOverlap(a.Vertices, b.Vertices) {
+		contact, _ := collideCores(a, b)
+		return -contact.Penetration, contact.Normal
+	}
+	pa, pb := closestPointsBetweenCores(a.Vertices, b.Vertices)
+	delta := pb.Add(pa.Mult(-1))
+	dist := delta.Magnitude()
+	if dist < narrowphaseEpsilon {
+		return -(a.Radius + b.Radius), Vec2.Vec2{X: 0, Y: 1}
+	}
+	return dist - a.Radius - b.Radius, delta.Mult(1 / dist)
+}
+
+// ShapeCastHit is where a swept shape first touches another
+type ShapeCastHit struct {
+	T      float64   // Fraction of the sweep travelled before touching
+	Normal Vec2.Vec2 // Points from the shape that was hit towards the swept shape
+	Point  Vec2.Vec2 // Contact point on the surface of the shape that was hit
+	Corner bool      // Point is a sharp vertex of the shape that was hit
+}
+
+// CastShape sweeps a along delta and returns where it first touches b. It uses conservative
+// advancement, which is exact for convex shapes that only translate. One-sided segments only
+// stop shapes that approach their solid side from the front.
+func CastShape(a WorldShape, delta Vec2.Vec2, b WorldShape) (ShapeCastHit, bool) {
+	if b.OneSided && Vec2.DotProduct(a.Center.Add(b.Vertices[0].Mult(-1)), b.Normal) < 0 {
+		return ShapeCastHit{}, false
+	}
+	t := 0.0
+	moved := a
+	for i := 0; i < 32; i++ {
+		dist, normal := ShapeDistance(moved, b)
+		rate := Vec2.DotProduct(delta, normal)
+		if dist <= CastTolerance {
+			// Touching shapes only block movement into each other
+			if rate <= 0 || (b.OneSided && Vec2.DotProduct(normal, b.Normal) >= 0) {
+				return ShapeCastHit{}, false
+			}
+			return castHit(moved, b, t, normal.Mult(-1)), true
+		}
+		if rate <= 0 {
+			return ShapeCastHit{}, false
+		}
+		t += dist / rate
+		if t > 1 {
+			return ShapeCastHit{}, false
+		}
+		moved = a.Translated(delta.Mult(t))
+	}
+	return ShapeCastHit{}, false
+}
+
+// Raycast returns where the segment from one point to another first enters a shape. A ray
+// that starts inside the shape does not hit it.
+func Raycast(from, to Vec2.Vec2, shape WorldShape) (ShapeCastHit, bool) {
+	point := WorldShape{Kind: ShapeCircle, Center: from, Vertices: []Vec2.Vec2{from}}
+	return CastShape(point, to.Add(from.Mult(-1)), shape)
+}
+
+func castHit(a, b WorldShape, t float64, normal Vec2.Vec2) ShapeCastHit {
+	_, pb := closestPointsBetweenCores(a.Vertices, b.Vertices)
+	hit := ShapeCastHit{T: t, Normal: normal, Point: pb.Add(normal.Mult(b.Radius))}
+	if b.Radius == 0 {
+		for _, v := range b.Vertices {
+			if Vec2.Distance(v, pb) < CastTolerance {
+				hit.Corner = true
+			}
+		}
+	}
+	return hit
+}
+
+// Translated returns a copy of the shape moved by offset
+func (ws WorldShape) Translated(offset Vec2.Vec2) WorldShape {
+	moved := ws
+	moved.Center = ws.Center.Add(offset)
+	moved.Vertices = make([]Vec2.Vec2, len(ws.Vertices))
+	for i, v := range ws.Vertices {
+		moved.Vertices[i] = v.Add(offset)
+	}
+	moved.Prev = ws.Prev.Add(offset)
+	moved.Next = ws.Next.Add(offset)
+	return moved
+}
@@ -0,0 +1,24 @@
+package components
+
+import "github.com/yohamta/donburi"
+
+// SleepData tracks how long a body has been at rest. A sleeping body is skipped by the
+// integrator until something moves it faster than the world's sleep speeds.
+type SleepData struct {
+	Asleep bool
+	Idle   float64 // Seconds spent below the sleep speeds
+}
+
+var Sleep = donburi.NewComponentType[SleepData]()
+
+// IsAsleep reports whether a body is sleeping
+func IsAsleep(entry *donburi.Entry) bool {
+	return entry.HasComponent(Sleep) && Sleep.Get(entry).Asleep
+}
+
+// WakeBody wakes a sleeping body and restarts its rest timer
+func WakeBody(entry *donburi.Entry) {
+	if entry.HasComponent(Sleep) {
+		*Sleep.Get(entry) = SleepData{}
+	}
+}
@@ -0,0 +1,98 @@
+package components
+
+import (
+	"image/color"
+	"math"
+	Vec2 "physengine/helpers/vec2"
+
+	"github.com/yohamta/donburi"
+)
+
+// SoftPoint is one point mass of a soft body, in world space
+type SoftPoint struct {
+	Pos         Vec2.Vec2
+	Vel         Vec2.Vec2
+	InverseMass float64 // 0 pins the point in place
+}
+
+// SoftSpring pulls two points of a soft body towards their rest distance
+type SoftSpring struct {
+	A, B       int
+	RestLength float64
+	Stiffness  float64 // Force per unit of stretch
+	Damping    float64 // Force per unit of relative speed along the spring
+}
+
+// SoftBodyData is a network of point masses joined by springs. Each point collides with
+// rigid bodies as a small circle; rigid bodies feel the contact impulses in return.
+// The entity's Transform follows the centre of the points.
+type SoftBodyData struct {
+	Points  []SoftPoint
+	Springs []SoftSpring
+	Hull    []int // Outline point indices in counter-clockwise order, used for pressure and drawing
+
+	PointRadius float64 // Collision radius of every point
+	Pressure    float64 // Outward force per unit of hull length at the rest area, 0 for no gas
+	RestArea    float64 // Hull area at which the gas pressure equals Pressure
+	Friction    float64 // Coulomb friction against rigid bodies
+
+	Fill    color.RGBA // Premultiplied, as color.RGBA always is
+	Outline color.RGBA
+}
+
+var SoftBody = donburi.NewComponentType[SoftBodyData]()
+
+// AddPoint appends a point with the given mass and returns its index
+func (s *SoftBodyData) AddPoint(pos Vec2.Vec2, mass float64) int {
+	p := SoftPoint{Pos: pos}
+	if mass > 0 {
+		p.InverseMass = 1 / mass
+	}
+	s.Points = append(s.Points, p)
+	return len(s.Points) - 1
+}
+
+// AddSpring joins points a and b with a spring at rest at their current distance
+func (s *SoftBodyData) AddSpring(a, b int, stiffness, damping float64) {
+	rest := Vec2.Distance(s.Points[a].Pos, s.Points[b].Pos)
+	s.Springs = append(s.Springs, SoftSpring{A: a, B: b, RestLength: rest, Stiffness: stiffness, Damping: damping})
+}
+
+// HullPoints returns the outline positions in hull order
+func (s *SoftBodyData) HullPoints() []Vec2.Vec2 {
+	points := make([]Vec2.Vec2, len(s.Hull))
+	for i, index := range s.Hull {
+		points[i] = s.Points[index].Pos
+	}
+	return points
+}
+
+// HullArea returns the signed area of the outline, positive while it winds counter-clockwise
+func (s *SoftBodyData) HullArea() float64 {
+	return signedArea(s.HullPoints())
+}
+
+// Center returns the mean position of the points
+func (s *SoftBodyData) Center() Vec2.Vec2 {
+	var sum Vec2.Vec2
+	if len(s.Points) == 0 {
+		return sum
+	}
+	for _, p := range s.Points {
+		sum.AddUpdate(p.Pos)
+	}
+	return sum.Mult(1 / float64(len(s.Points)))
+}
+
+// Bounds returns the world-space box around all points, grown by the point radius
+func (s *SoftBodyData) Bounds() (Vec2.Vec2, Vec2.Vec2) {
+	min := Vec2.Vec2{X: math.Inf(1), Y: math.Inf(1)}
+	max := Vec2.Vec2{X: math.Inf(-1), Y: math.Inf(-1)}
+	for _, p := range s.Points {
+		min.X = math.Min(min.X, p.Pos.X-s.PointRadius)
+		min.Y = math.Min(min.Y, p.Pos.Y-s.PointRadius)
+		max.X = math.Max(max.X, p.Pos.X+s.PointRadius)
+		max.Y = math.Max(max.Y, p.Pos.Y+s.PointRadius)
+	}
+	return min, max
+}
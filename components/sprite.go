@@ -1,12 +1,86 @@
 package components
 
-import(
+import (
+	"image"
+	"image/color"
+	Vec2 "physengine/helpers/vec2"
+
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/yohamta/donburi"
 )
 
-type SpriteData struct{
-	Image *ebiten.Image
+// SpriteData is a renderable image with tint, sheet sub-rectangles and frame animation.
+// Sprites that share Image are batched into a single draw call.
+type SpriteData struct {
+	Image       *ebiten.Image
+	SourceRect  image.Rectangle // Region of Image to draw, empty means the whole image
+	Tint        color.RGBA      // Multiplied with the image colour
+	Alpha       float64         // 0 = invisible, 1 = opaque
+	FlipX       bool
+	FlipY       bool
+	PivotOffset Vec2.Vec2 // Pivot in pixels relative to the centre of the source rectangle
+
+	// Frame animation; when Frames is set it overrides SourceRect
+	Frames        []image.Rectangle
+	FrameDuration float64 // Seconds per frame
+	CurrentFrame  int
+	FrameTimer    float64
+	Playing       bool
+	Loop          bool
+}
+
+var Sprite = donburi.NewComponentType[SpriteData](SpriteData{
+	Tint:  color.RGBA{R: 255, G: 255, B: 255, A: 255},
+	Alpha: 1,
+	Loop:  true,
+})
+
+// ZIndexData controls draw order: lower layers draw first, Z orders sprites within a layer
+type ZIndexData struct {
+	Layer int
+	Z     float64
 }
 
-var Sprite = donburi.NewComponentType[SpriteData]()
\ No newline at end of file
+var ZIndex = donburi.NewComponentType[ZIndexData]()
+
+// Source returns the rectangle of the sheet drawn this frame
+func (s *SpriteData) Source() image.Rectangle {
+	if len(s.Frames) > 0 {
+		return s.Frames[s.CurrentFrame%len(s.Frames)]
+	}
+	if s.SourceRect.Empty() && s.Image != nil {
+		return s.Image.Bounds()
+	}
+	return s.SourceRect
+}
+
+// SpriteSheetFrames slices a sprite sheet into count frames of frameWidth x frameHeight,
+// reading left to right, top to bottom
+func SpriteSheetFrames(sheet *ebiten.Image, frameWidth, frameHeight, count int) []image.Rectangle {
+	bounds := sheet.Bounds()
+	columns := bounds.Dx() / frameWidth
+	if columns == 0 {
+		return nil
+	}
+	frames := make([]image.Rectangle, 0, count)
+	for i := 0; i < count; i++ {
+		x := bounds.Min.X + (i%columns)*frameWidth
+		y := bounds.Min.Y + (i/columns)*frameHeight
+		frames = append(frames, image.Rect(x, y, x+frameWidth, y+frameHeight))
+	}
+	return frames
+}
+
+// PlayAnimation starts a frame animation on a sprite from its first frame
+func PlayAnimation(entry *donburi.Entry, frames []image.Rectangle, frameDuration float64, loop bool) {
+	sprite := Sprite.Get(entry)
+	if sprite == nil {
+		return
+	}
+	sprite.Frames = frames
+	sprite.FrameDuration = frameDuration
+	sprite.Loop = loop
+	sprite.CurrentFrame = 0
+	sprite.FrameTimer = 0
+	sprite.Playing = true
+}
@@ -0,0 +1,12 @@
+package components
+
+import "github.com/yohamta/donburi"
+
+// StaticBody marks level geometry that never moves. The broadphase caches its shapes
+// and never tests static bodies against each other.
+var StaticBody = donburi.NewTag()
+
+// IsStatic reports whether an entity is static level geometry
+func IsStatic(entry *donburi.Entry) bool {
+	return entry.HasComponent(StaticBody)
+}
@@ -0,0 +1,73 @@
+package components
+
+import (
+	Vec2 "physengine/helpers/vec2"
+
+	"github.com/yohamta/donburi"
+)
+
+// defaultTelemetryCapacity keeps half a minute of steps at 120 steps per second
+const defaultTelemetryCapacity = 3600
+
+// TelemetrySample holds the conservation and contact measurements of one physics step.
+// Totals cover every dynamic body at the end of the step; the Resolver fields are the
+// change the collision step alone made to them.
+type TelemetrySample struct {
+	Step            int       `json:"step"`
+	Time            float64   `json:"time"` // Simulated seconds
+	Momentum        Vec2.Vec2 `json:"momentum"`
+	AngularMomentum float64   `json:"angularMomentum"` // About the world origin, including spin
+	Kinetic         float64   `json:"kinetic"`         // Linear and rotational
+
+	Contacts        int     `json:"contacts"`
+	MaxPenetration  float64 `json:"maxPenetration"`
+	MeanPenetration float64 `json:"meanPenetration"`
+
+	ResolverMomentum        Vec2.Vec2 `json:"resolverMomentum"`
+	ResolverAngularMomentum float64   `json:"resolverAngularMomentum"`
+	ResolverKinetic         float64   `json:"resolverKinetic"` // Should never be positive
+}
+
+// TelemetryData records a rolling history of TelemetrySample, oldest first once full
+type TelemetryData struct {
+	Capacity int             // Samples kept, 0 for the default
+	Pending  TelemetrySample // Sample of the step in progress
+	samples  []TelemetrySample
+	next     int
+}
+
+var Telemetry = donburi.NewComponentType[TelemetryData]()
+
+// Add appends a sample, dropping the oldest one when the history is full
+func (t *TelemetryData) Add(sample TelemetrySample) {
+	capacity := t.Capacity
+	if capacity <= 0 {
+		capacity = defaultTelemetryCapacity
+	}
+	if len(t.samples) < capacity {
+		t.samples = append(t.samples, sample)
+		return
+	}
+	t.samples[t.next%len(t.samples)] = sample
+	t.next = (t.next + 1) % len(t.samples)
+}
+
+// Samples returns the recorded history in step order
+func (t *TelemetryData) Samples() []TelemetrySample {
+	samples := make([]TelemetrySample, 0, len(t.samples))
+	samples = append(samples, t.samples[t.next:]...)
+	return append(samples, t.samples[:t.next]...)
+}
+
+// Last returns the most recent sample, false if nothing was recorded yet
+func (t *TelemetryData) Last() (TelemetrySample, bool) {
+	if len(t.samples) == 0 {
+		return TelemetrySample{}, false
+	}
+	return t.samples[(t.next+len(t.samples)-1)%len(t.samples)], true
+}
+
+// Reset clears the history
+func (t *TelemetryData) Reset() {
+	t.samples, t.next, t.Pending = nil, 0, TelemetrySample{}
+}
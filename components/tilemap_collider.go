@@ -0,0 +1,212 @@
+package components
+
+import (
+	Vec2 "physengine/helpers/vec2"
+
+	"github.com/yohamta/donburi"
+)
+
+type TilemapMode int
+
+const (
+	TilemapRects  TilemapMode = iota // Solid tiles merged into as few boxes as possible
+	TilemapChains                    // Outlines of solid areas as chain loops, smooth to slide along
+)
+
+// TilemapColliderData turns a grid of solid tiles into static collision shapes. Tiles[row][column],
+// row 0 is the top row and the body position is the top-left corner of the map.
+type TilemapColliderData struct {
+	Tiles    [][]bool
+	TileSize float64
+	Mode     TilemapMode
+
+	shapes []Shape
+	built  bool
+}
+
+var TilemapCollider = donburi.NewComponentType[TilemapColliderData]()
+
+// SetTile changes one tile; the shapes are rebuilt on next use. Static bodies are cached by
+// the broadphase, so call InvalidateStaticBroadphase after editing a live map.
+func (t *TilemapColliderData) SetTile(column, row int, solid bool) {
+	if row < 0 || row >= len(t.Tiles) || column < 0 || column >= len(t.Tiles[row]) {
+		return
+	}
+	t.Tiles[row][column] = solid
+	t.built = false
+}
+
+// Shapes returns the merged collision shapes of the map in body space
+func (t *TilemapColliderData) Shapes() []Shape {
+	if !t.built {
+		if t.Mode == TilemapChains {
+			t.shapes = nil
+			for _, loop := range TraceTileOutlines(t.Tiles, t.TileSize) {
+				t.shapes = append(t.shapes, ChainShapes(loop, true, 0)...)
+			}
+		} else {
+			t.shapes = MergeTileRects(t.Tiles, t.TileSize)
+		}
+		t.built = true
+	}
+	return t.shapes
+}
+
+// ParseTiles reads a tile grid from rows of text, where '#' marks a solid tile
+func ParseTiles(rows []string) [][]bool {
+	tiles := make([][]bool, len(rows))
+	for y, row := range rows {
+		tiles[y] = make([]bool, len(row))
+		for x, c := range row {
+			tiles[y][x] = c == '#'
+		}
+	}
+	return tiles
+}
+
+func tileSolid(tiles [][]bool, column, row int) bool {
+	return row >= 0 && row < len(tiles) && column >= 0 && column < len(tiles[row]) && tiles[row][column]
+}
+
+// MergeTileRects greedily merges solid tiles into boxes: each box grows right along its row,
+// then down while the whole span below is solid
+func MergeTileRects(tiles [][]bool, size float64) []Shape {
+	used := make([][]bool, len(tiles))
+	for y := range tiles {
+		used[y] = make([]bool, len(tiles[y]))
+	}
+	free := func(x, y int) bool {
+		return tileSolid(tiles, x, y) && !used[y][x]
+	}
+
+	var shapes []Shape
+	for y := range tiles {
+		for x := range tiles[y] {
+			if !free(x, y) {
+				continue
+			}
+			w := 1
+			for free(x+w, y) {
+				w++
+			}
+			h := 1
+			for grow := true; grow; {
+				for i := 0; i < w; i++ {
+					if !free(x+i, y+h) {
+						grow = false
+						break
+					}
+				}
+				if grow {
+					h++
+				}
+			}
+			for j := 0; j < h; j++ {
+				for i := 0; i < w; i++ {
+					used[y+j][x+i] = true
+				}
+			}
+			shapes = append(shapes, Shape{
+				Kind:        ShapeBox,
+				Offset:      Vec2.Vec2{X: (float64(x) + float64(w)/2) * size, Y: -(float64(y) + float64(h)/2) * size},
+				HalfExtents: Vec2.Vec2{X: float64(w) * size / 2, Y: float64(h) * size / 2},
+			})
+		}
+	}
+	return shapes
+}
+
+// tileCorner is a grid corner with Y pointing up, so column x, row y is the corner (x, -y)
+type tileCorner struct{ x, y int }
+
+type tileEdge struct{ from, to tileCorner }
+
+// TraceTileOutlines traces the boundaries between solid and empty tiles into closed loops.
+// Loops run clockwise around solid areas (and anticlockwise around holes), so the solid
+// side is always on the right. Collinear points are dropped.
+func TraceTileOutlines(tiles [][]bool, size float64) [][]Vec2.Vec2 {
+	var edges []tileEdge
+	for y := range tiles {
+		for x := range tiles[y] {
+			if !tiles[y][x] {
+				continue
+			}
+			tl := tileCorner{x, -y}
+			tr := tileCorner{x + 1, -y}
+			br := tileCorner{x + 1, -y - 1}
+			bl := tileCorner{x, -y - 1}
+			if !tileSolid(tiles, x, y-1) {
+				edges = append(edges, tileEdge{tl, tr})
+			}
+			if !tileSolid(tiles, x+1, y) {
+				edges = append(edges, tileEdge{tr, br})
+			}
+			if !tileSolid(tiles, x, y+1) {
+				edges = append(edges, tileEdge{br, bl})
+			}
+			if !tileSolid(tiles, x-1, y) {
+				edges = append(edges, tileEdge{bl, tl})
+			}
+		}
+	}
+
+	outgoing := map[tileCorner][]int{}
+	for i, e := range edges {
+		outgoing[e.from] = append(outgoing[e.from], i)
+	}
+	used := make([]bool, len(edges))
+
+	// Where two solid tiles touch only at a corner, turning right keeps each tile's outline separate
+	next := func(e tileEdge) int {
+		dx, dy := e.to.x-e.from.x, e.to.y-e.from.y
+		best, bestRank := -1, 4
+		for _, i := range outgoing[e.to] {
+			if used[i] {
+				continue
+			}
+			nx, ny := edges[i].to.x-edges[i].from.x, edges[i].to.y-edges[i].from.y
+			rank := 3
+			switch {
+			case nx == dy && ny == -dx:
+				rank = 0 // Right turn
+			case nx == dx && ny == dy:
+				rank = 1
+			case nx == -dy && ny == dx:
+				rank = 2
+			}
+			if rank < bestRank {
+				best, bestRank = i, rank
+			}
+		}
+		return best
+	}
+
+	var loops [][]Vec2.Vec2
+	for start := range edges {
+		if used[start] {
+			continue
+		}
+		var corners []tileCorner
+		for i := start; i >= 0; i = next(edges[i]) {
+			used[i] = true
+			corners = append(corners, edges[i].from)
+			if edges[i].to == edges[start].from {
+				break
+			}
+		}
+		loop := make([]Vec2.Vec2, 0, len(corners))
+		for i, c := range corners {
+			prev := corners[(i-1+len(corners))%len(corners)]
+			following := corners[(i+1)%len(corners)]
+			// Drop corners in the middle of a straight run
+			if (prev.x-c.x)*(following.y-c.y)-(prev.y-c.y)*(following.x-c.x) == 0 {
+				continue
+			}
+			loop = append(loop, Vec2.Vec2{X: float64(c.x) * size, Y: float64(c.y) * size})
+		}
+		if len(loop) >= 3 {
+			loops = append(loops, loop)
+		}
+	}
+	return loops
+}
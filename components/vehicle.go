@@ -0,0 +1,61 @@
+package components
+
+import (
+	"math"
+
+	"github.com/yohamta/donburi"
+)
+
+// VehicleInput is read by the vehicle system every step
+type VehicleInput interface {
+	Throttle() float64 // -1 full reverse, 1 full forward
+	Brake() float64    // 0 released, 1 full brake
+}
+
+// TorquePoint is one sample of a torque curve: the drive torque available at a wheel speed
+type TorquePoint struct {
+	Speed  float64 // Wheel angular speed in rad/s
+	Torque float64
+}
+
+// VehicleWheel is one wheel of a vehicle
+type VehicleWheel struct {
+	Joint  *donburi.Entry // Entity with the Joint and WheelJoint components
+	Driven bool           // Receives drive torque from the throttle
+	Brakes bool           // Slowed by the brake input
+}
+
+// VehicleData drives the wheel joint motors of a chassis from its input. Forward is to
+// the right of the chassis, so driven wheels spin clockwise.
+type VehicleData struct {
+	Input  VehicleInput
+	Wheels []VehicleWheel
+
+	MaxSpeed          float64       // Top wheel speed in rad/s
+	TorqueCurve       []TorquePoint // Drive torque per driven wheel, sorted by speed
+	BrakeTorque       float64       // Torque per braking wheel at full brake
+	RollingResistance float64       // Torque slowing the wheels when neither throttle nor brake is applied
+}
+
+var Vehicle = donburi.NewComponentType[VehicleData]()
+
+// TorqueAt returns the drive torque at a wheel speed, interpolating linearly between
+// the curve points and holding the end values outside them
+func (v *VehicleData) TorqueAt(speed float64) float64 {
+	curve := v.TorqueCurve
+	if len(curve) == 0 {
+		return 0
+	}
+	speed = math.Abs(speed)
+	if speed <= curve[0].Speed {
+		return curve[0].Torque
+	}
+	for i := 1; i < len(curve); i++ {
+		if speed <= curve[i].Speed {
+			p0, p1 := curve[i-1], curve[i]
+			t := (speed - p0.Speed) / (p1.Speed - p0.Speed)
+			return p0.Torque + t*(p1.Torque-p0.Torque)
+		}
+	}
+	return curve[len(curve)-1].Torque
+}
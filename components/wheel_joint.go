@@ -0,0 +1,47 @@
+package components
+
+import (
+	Vec2 "physengine/helpers/vec2"
+
+	"github.com/yohamta/donburi"
+)
+
+// WheelJointData lets body B slide along an axis fixed in body A, held by a spring, and
+// spin freely about its anchor, optionally driven by a motor. Anchors are relative to the
+// body positions, which are the centres of mass.
+type WheelJointData struct {
+	LocalAnchorA Vec2.Vec2
+	LocalAnchorB Vec2.Vec2
+	LocalAxis    Vec2.Vec2 // Suspension axis in body A's frame
+
+	Frequency    float64 // Suspension stiffness in Hz, 0 makes the axis rigid
+	DampingRatio float64 // 1 is critically damped
+
+	EnableMotor    bool
+	MotorSpeed     float64 // Target angular velocity of B relative to A in rad/s
+	MaxMotorTorque float64
+
+	// Impulses of the last step, reused to warm start the next one
+	Impulse       float64 // Point-to-line impulse perpendicular to the axis
+	SpringImpulse float64
+	MotorImpulse  float64
+}
+
+var WheelJoint = donburi.NewComponentType[WheelJointData]()
+
+// WheelJointDefaults is a soft, well damped suspension along body A's Y axis
+var WheelJointDefaults = WheelJointData{
+	LocalAxis:    Vec2.Vec2{X: 0, Y: 1},
+	Frequency:    4,
+	DampingRatio: 0.7,
+}
+
+// Translation returns how far the wheel has moved along the axis from its rest position
+func (j *WheelJointData) Translation(a, b *donburi.Entry) float64 {
+	trA := Transform.Get(a)
+	trB := Transform.Get(b)
+	pA := trA.Pos.Add(RotatePoint(j.LocalAnchorA, trA.Rot))
+	pB := trB.Pos.Add(RotatePoint(j.LocalAnchorB, trB.Rot))
+	axis := RotatePoint(j.LocalAxis.Normalized(), trA.Rot)
+	return Vec2.DotProduct(pB.Add(pA.Mult(-1)), axis)
+}
@@ -0,0 +1,71 @@
+// Package diagnostics measures conserved quantities and contact penetration of the physics
+// world, so changes to the solvers can be checked numerically, and exports the telemetry
+// recorded by systems.UpdateTelemetry.
+package diagnostics
+
+import (
+	"physengine/components"
+	Vec2 "physengine/helpers/vec2"
+
+	"github.com/yohamta/donburi"
+	"github.com/yohamta/donburi/filter"
+)
+
+// Totals are the summed momentum and kinetic energy of the dynamic bodies
+type Totals struct {
+	Momentum        Vec2.Vec2
+	AngularMomentum float64 // About the world origin, including spin
+	Kinetic         float64 // Linear and rotational
+}
+
+// Sub returns the change from before to t
+func (t Totals) Sub(before Totals) Totals {
+	return Totals{
+		Momentum:        t.Momentum.Add(before.Momentum.Mult(-1)),
+		AngularMomentum: t.AngularMomentum - before.AngularMomentum,
+		Kinetic:         t.Kinetic - before.Kinetic,
+	}
+}
+
+var dynamicBodies = donburi.NewQuery(filter.And(
+	filter.Contains(components.Transform, components.MassComponent),
+	filter.Or(filter.Contains(components.Velocity), filter.Contains(components.AngularVelocity)),
+))
+
+// Measure sums the momentum and kinetic energy of every body with a finite mass. Static
+// bodies and particles without a MassComponent are left out.
+func Measure(w donburi.World) Totals {
+	var total Totals
+	for entry := range dynamicBodies.Iter(w) {
+		mass := components.MassComponent.Get(entry)
+		if mass.InverseMass > 0 && entry.HasComponent(components.Velocity) {
+			m := 1 / mass.InverseMass
+			pos := components.Transform.Get(entry).Pos
+			vel := components.Velocity.Get(entry).Velocity
+			total.Momentum.AddUpdate(vel.Mult(m))
+			total.AngularMomentum += m * Vec2.CrossProductVecVec(pos, vel)
+			total.Kinetic += 0.5 * m * vel.SquareMagnitude()
+		}
+		if mass.InverseInertia > 0 && entry.HasComponent(components.AngularVelocity) {
+			inertia := 1 / mass.InverseInertia
+			w := components.AngularVelocity.Get(entry).AngularVelocity
+			total.AngularMomentum += inertia * w
+			total.Kinetic += 0.5 * inertia * w * w
+		}
+	}
+	return total
+}
+
+// Penetration returns the number of contacts and their largest and mean penetration depth
+func Penetration(contacts []components.ContactData) (count int, max, mean float64) {
+	for _, contact := range contacts {
+		if contact.Penetration > max {
+			max = contact.Penetration
+		}
+		mean += contact.Penetration
+	}
+	if len(contacts) > 0 {
+		mean /= float64(len(contacts))
+	}
+	return len(contacts), max, mean
+}
@@ -0,0 +1,72 @@
+package diagnostics
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"physengine/components"
+	"strconv"
+)
+
+// csvHeader names the columns written by WriteCSV
+var csvHeader = []string{
+	"step", "time", "momentum_x", "momentum_y", "angular_momentum", "kinetic",
+	"contacts", "max_penetration", "mean_penetration",
+	"resolver_momentum_x", "resolver_momentum_y", "resolver_angular_momentum", "resolver_kinetic",
+}
+
+// WriteCSV writes the samples as CSV with a header row, one row per step
+func WriteCSV(w io.Writer, samples []components.TelemetrySample) error {
+	out := csv.NewWriter(w)
+	if err := out.Write(csvHeader); err != nil {
+		return err
+	}
+	f := func(v float64) string { return strconv.FormatFloat(v, 'g', -1, 64) }
+	for _, s := range samples {
+		row := []string{
+			strconv.Itoa(s.Step), f(s.Time), f(s.Momentum.X), f(s.Momentum.Y), f(s.AngularMomentum), f(s.Kinetic),
+			strconv.Itoa(s.Contacts), f(s.MaxPenetration), f(s.MeanPenetration),
+			f(s.ResolverMomentum.X), f(s.ResolverMomentum.Y), f(s.ResolverAngularMomentum), f(s.ResolverKinetic),
+		}
+		if err := out.Write(row); err != nil {
+			return err
+		}
+	}
+	out.Flush()
+	return out.Error()
+}
+
+// WriteJSON writes the samples as a JSON array
+func WriteJSON(w io.Writer, samples []components.TelemetrySample) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(samples)
+}
+
+// Export writes the samples to telemetry.csv and telemetry.json in dir
+func Export(dir string, samples []components.TelemetrySample) error {
+	for name, write := range map[string]func(io.Writer, []components.TelemetrySample) error{
+		"telemetry.csv":  WriteCSV,
+		"telemetry.json": WriteJSON,
+	} {
+		if err := writeFile(filepath.Join(dir, name), samples, write); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeFile(path string, samples []components.TelemetrySample, write func(io.Writer, []components.TelemetrySample) error) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("exporting telemetry: %w", err)
+	}
+	if err := write(file, samples); err != nil {
+		file.Close()
+		return fmt.Errorf("exporting telemetry to %s: %w", path, err)
+	}
+	return file.Close()
+}
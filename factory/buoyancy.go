@@ -0,0 +1,91 @@
+package factory
+
+import (
+	"image/color"
+	"math"
+	"physengine/components"
+	Vec2 "physengine/helpers/vec2"
+
+	"github.com/yohamta/donburi"
+	"github.com/yohamta/donburi/ecs"
+)
+
+// Water is a buoyancy volume filled with still water
+var Water = components.BuoyancyVolumeData{
+	Density:     0.001,
+	LinearDrag:  2,
+	AngularDrag: 2,
+	Color:       color.RGBA{R: 16, G: 49, B: 93, A: 140},
+}
+
+// CreateBuoyancyVolume creates a volume of fluid with the given world-space outline, which
+// may be concave
+func CreateBuoyancyVolume(ecs *ecs.ECS, outline []Vec2.Vec2, fluid components.BuoyancyVolumeData) (*donburi.Entry, error) {
+	if err := fluid.SetPolygon(outline); err != nil {
+		return nil, err
+	}
+	return createBuoyancyVolume(ecs, fluid), nil
+}
+
+// CreateBuoyancyRect creates a rectangular volume of fluid from min to max
+func CreateBuoyancyRect(ecs *ecs.ECS, min, max Vec2.Vec2, fluid components.BuoyancyVolumeData) *donburi.Entry {
+	fluid.SetRect(min, max)
+	return createBuoyancyVolume(ecs, fluid)
+}
+
+func createBuoyancyVolume(ecs *ecs.ECS, fluid components.BuoyancyVolumeData) *donburi.Entry {
+	entity := ecs.World.Create(components.BuoyancyVolume, components.Transform)
+	entry := ecs.World.Entry(entity)
+	components.BuoyancyVolume.SetValue(entry, fluid)
+	min, max := fluid.Bounds()
+	tr := components.Transform.Get(entry)
+	tr.Pos = min.Add(max).Mult(0.5)
+	tr.Scale = Vec2.Vec2{X: 1, Y: 1}
+	return entry
+}
+
+// CreateBuoyancyDemo builds a pool with a current that carries floating bodies along, and a
+// bowl-shaped pond of denser brine that floats rubber but not steel
+func CreateBuoyancyDemo(ecs *ecs.ECS) {
+	CreateChain(ecs, []Vec2.Vec2{{X: -900, Y: 600}, {X: -900, Y: -400}, {X: 100, Y: -400}, {X: 100, Y: 0}})
+	current := Water
+	current.Flow = Vec2.Vec2{X: 80, Y: 0}
+	CreateBuoyancyRect(ecs, Vec2.Vec2{X: -900, Y: -400}, Vec2.Vec2{X: 100, Y: -100}, current)
+
+	// The pond's outline follows the bowl of its banks
+	bowl := []Vec2.Vec2{{X: 250, Y: 0}, {X: 350, Y: -250}, {X: 500, Y: -350}, {X: 650, Y: -250}, {X: 750, Y: 0}}
+	CreateChain(ecs, append(bowl, Vec2.Vec2{X: 750, Y: 600}))
+	brine := Water
+	brine.Density = 0.0025
+	brine.Color = color.RGBA{R: 22, G: 66, B: 60, A: 140}
+	CreateBuoyancyVolume(ecs, []Vec2.Vec2{{X: 290, Y: -100}, {X: 350, Y: -250}, {X: 500, Y: -350}, {X: 650, Y: -250}, {X: 710, Y: -100}}, brine)
+
+	wood, _ := components.MaterialPreset("wood")
+	ice, _ := components.MaterialPreset("ice")
+	tyre, _ := components.MaterialPreset("tyre")
+	steel, _ := components.MaterialPreset("steel")
+	CreateCompoundBody(ecs, Vec2.Vec2{X: -800, Y: 0}, []components.Shape{
+		{Kind: components.ShapeBox, HalfExtents: Vec2.Vec2{X: 40, Y: 40}},
+	}, wood)
+	plank := CreateCompoundBody(ecs, Vec2.Vec2{X: -600, Y: 50}, []components.Shape{
+		{Kind: components.ShapeBox, HalfExtents: Vec2.Vec2{X: 80, Y: 12}},
+	}, wood)
+	components.Transform.Get(plank).Rot = math.Pi / 3
+	CreateCompoundBody(ecs, Vec2.Vec2{X: -400, Y: 0}, []components.Shape{
+		{Kind: components.ShapeCircle, Radius: 30},
+	}, ice)
+	CreateCompoundBody(ecs, Vec2.Vec2{X: -250, Y: 0}, []components.Shape{
+		{Kind: components.ShapeCapsule, Radius: 15, HalfLength: 30},
+	}, wood)
+	CreateCompoundBody(ecs, Vec2.Vec2{X: -100, Y: 0}, []components.Shape{
+		{Kind: components.ShapeCircle, Radius: 20},
+	}, tyre)
+
+	// A tyre-rubber ball sinks in water but floats in the brine; steel sinks in both
+	CreateCompoundBody(ecs, Vec2.Vec2{X: 450, Y: 100}, []components.Shape{
+		{Kind: components.ShapeCircle, Radius: 25},
+	}, tyre)
+	CreateCompoundBody(ecs, Vec2.Vec2{X: 550, Y: 100}, []components.Shape{
+		{Kind: components.ShapeBox, HalfExtents: Vec2.Vec2{X: 15, Y: 15}},
+	}, steel)
+}
@@ -11,11 +11,21 @@ import (
 )
 
 func CreateCamera(ecs *ecs.ECS) *donburi.Entry {
+	return CreateViewportCamera(ecs, components.FullScreen, 0, components.LayerAll)
+}
+
+// CreateViewportCamera creates a camera rendering the given layers into a normalized screen rectangle
+func CreateViewportCamera(ecs *ecs.ECS, viewport components.ViewportRect, priority int, layerMask uint32) *donburi.Entry {
 	entity := ecs.World.Create(components.Camera, components.Transform)
 	entry := ecs.World.Entry(entity)
 	components.Camera.SetValue(entry, components.CameraData{
-		ViewportSizeX:      1000,
-		ViewportSizeY:      1000,
+		Viewport:           viewport,
+		Priority:           priority,
+		LayerMask:          layerMask,
+		ViewportSizeX:      1000 * viewport.W, // Replaced by ResizeCameras once the window layout is known
+		ViewportSizeY:      1000 * viewport.H,
+		ViewportPosX:       1000 * viewport.X,
+		ViewportPosY:       1000 * viewport.Y,
 		LastScreenMousePos: Vec2.Vec2{X: 500, Y: 500}, // Initialize to center of screen
 		Op:                 ebiten.DrawImageOptions{},
 		Zoom:               Vec2.Vec2{X: 0.5, Y: 0.5},
@@ -29,3 +39,10 @@ func CreateCamera(ecs *ecs.ECS) *donburi.Entry {
 
 	return entry
 }
+
+// CreateSplitScreenCameras creates two side-by-side cameras sharing the window
+func CreateSplitScreenCameras(ecs *ecs.ECS) (*donburi.Entry, *donburi.Entry) {
+	left := CreateViewportCamera(ecs, components.ViewportRect{X: 0, Y: 0, W: 0.5, H: 1}, 0, components.LayerAll)
+	right := CreateViewportCamera(ecs, components.ViewportRect{X: 0.5, Y: 0, W: 0.5, H: 1}, 0, components.LayerAll)
+	return left, right
+}
@@ -0,0 +1,53 @@
+package factory
+
+import (
+	"physengine/components"
+	Vec2 "physengine/helpers/vec2"
+
+	"github.com/yohamta/donburi"
+	"github.com/yohamta/donburi/ecs"
+)
+
+var CapsulePrefab = Prefab{
+	Name:      "capsule",
+	Shape:     PrefabShape{Type: ShapeCapsule, Radius: 30, HalfLength: 40},
+	Material:  "wood",
+	Draggable: true,
+}
+
+func init() {
+	RegisterPrefab(CapsulePrefab)
+}
+
+// CreateCapsule creates an upright capsule body, the usual shape for characters
+func CreateCapsule(ecs *ecs.ECS, pos Vec2.Vec2, vel Vec2.Vec2) *donburi.Entry {
+	return mustSpawn(ecs, CapsulePrefab, pos, &PrefabOverrides{Velocity: &vel})
+}
+
+// CreateRamp creates a static line from a to b in world space. A one-sided ramp only
+// blocks bodies on the left of a->b.
+func CreateRamp(ecs *ecs.ECS, a, b Vec2.Vec2, oneSided bool) *donburi.Entry {
+	mid := a.Add(b).Mult(0.5)
+	return mustSpawn(ecs, Prefab{
+		Name: "ramp",
+		Shape: PrefabShape{
+			Type:     ShapeSegment,
+			A:        a.Add(mid.Mult(-1)),
+			B:        b.Add(mid.Mult(-1)),
+			OneSided: oneSided,
+		},
+		Static:   true,
+		Material: "steel",
+	}, mid, nil)
+}
+
+// CreateCapsuleSegmentDemo drops capsules onto a ramp and through a one-sided platform
+func CreateCapsuleSegmentDemo(ecs *ecs.ECS) {
+	CreateRamp(ecs, Vec2.Vec2{X: -700, Y: -350}, Vec2.Vec2{X: -300, Y: -500}, false)
+	CreateRamp(ecs, Vec2.Vec2{X: 300, Y: -400}, Vec2.Vec2{X: 700, Y: -400}, true)
+
+	CreateCapsule(ecs, Vec2.Vec2{X: -600, Y: -200}, Vec2.Vec2{X: 30, Y: -120})
+	// Passes up through the platform, then lands on it on the way back
+	capsule := CreateCapsule(ecs, Vec2.Vec2{X: 500, Y: -550}, Vec2.Vec2{X: 0, Y: 150})
+	components.SetAngularVelocity(capsule, 0.5)
+}
@@ -0,0 +1,29 @@
+package factory
+
+import (
+	"physengine/assets"
+	"physengine/components"
+	Vec2 "physengine/helpers/vec2"
+
+	"github.com/yohamta/donburi"
+	"github.com/yohamta/donburi/ecs"
+)
+
+// CreateCharacter creates a capsule-shaped platformer character driven by input
+func CreateCharacter(ecs *ecs.ECS, pos Vec2.Vec2, input components.CharacterInput) *donburi.Entry {
+	entry := createKinematicBody(ecs, pos, components.CapsuleCollider, components.CharacterController, components.Drawable)
+	components.CapsuleCollider.SetValue(entry, components.CapsuleColliderData{Radius: 30, HalfLength: 40})
+	components.CharacterController.Get(entry).Input = input
+	components.Drawable.Get(entry).Sprite = assets.Image("player.png")
+	// Fit the 124x192 sprite to the 60x140 capsule
+	components.Transform.Get(entry).Scale = Vec2.Vec2{X: 0.5, Y: 0.73}
+	return entry
+}
+
+// CreateMovingPlatform creates a kinematic box that travels back and forth between a and b
+func CreateMovingPlatform(ecs *ecs.ECS, a, b Vec2.Vec2, halfExtents Vec2.Vec2, speed float64) *donburi.Entry {
+	entry := createKinematicBody(ecs, a, components.AABB_Component, components.PlatformPath)
+	components.AABB_Component.SetValue(entry, components.AABB_Data{Min: halfExtents.Mult(-1), Max: halfExtents})
+	components.PlatformPath.SetValue(entry, components.PlatformPathData{A: a, B: b, Speed: speed})
+	return entry
+}
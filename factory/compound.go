@@ -0,0 +1,43 @@
+package factory
+
+import (
+	"physengine/components"
+	Vec2 "physengine/helpers/vec2"
+
+	"github.com/yohamta/donburi"
+	"github.com/yohamta/donburi/ecs"
+)
+
+// CreateCompoundBody creates a dynamic body made of several shapes. Mass, inertia and the
+// centre of mass come from the shapes and the material density, and the body position is
+// moved to the centre of mass so the body rotates about it.
+func CreateCompoundBody(ecs *ecs.ECS, pos Vec2.Vec2, shapes []components.Shape, material components.MaterialData) *donburi.Entry {
+	entity := ecs.World.Create(components.MaterialComponent, components.Transform, components.CompoundCollider, components.MassComponent, components.Velocity, components.AngularVelocity, components.Torque)
+	entry := ecs.World.Entry(entity)
+	components.SetPos(entry, pos)
+	components.Transform.Get(entry).Scale = Vec2.Vec2{X: 1, Y: 1}
+	components.MaterialComponent.SetValue(entry, material)
+	components.CompoundCollider.Get(entry).Shapes = append([]components.Shape(nil), shapes...)
+
+	density := material.Density
+	if density <= 0 {
+		density = 0.001
+	}
+	components.RecenterBody(entry, density)
+	components.SetMassFromShapes(entry, density)
+
+	return entry
+}
+
+// CreateHammer creates a wooden handle with a heavy steel head, spinning about its centre of mass
+func CreateHammer(ecs *ecs.ECS, pos Vec2.Vec2, vel Vec2.Vec2) *donburi.Entry {
+	wood, _ := components.MaterialPreset("wood")
+	steel, _ := components.MaterialPreset("steel")
+	entry := CreateCompoundBody(ecs, pos, []components.Shape{
+		{Kind: components.ShapeBox, HalfExtents: Vec2.Vec2{X: 10, Y: 80}},
+		{Kind: components.ShapeBox, Offset: Vec2.Vec2{X: 0, Y: 100}, HalfExtents: Vec2.Vec2{X: 50, Y: 20}, Density: steel.Density},
+	}, wood)
+	components.Velocity.Get(entry).Velocity = vel
+	components.SetAngularVelocity(entry, 1.0)
+	return entry
+}
@@ -0,0 +1,19 @@
+package factory
+
+import (
+	"physengine/components"
+
+	"github.com/yohamta/donburi"
+	"github.com/yohamta/donburi/ecs"
+)
+
+// CreateDebugDraw creates the debug overlay settings with collider outlines and stats enabled
+func CreateDebugDraw(ecs *ecs.ECS) *donburi.Entry {
+	entity := ecs.World.Create(components.DebugDraw)
+	entry := ecs.World.Entry(entity)
+	components.DebugDraw.SetValue(entry, components.DebugDrawData{
+		Shapes: true,
+		Stats:  true,
+	})
+	return entry
+}
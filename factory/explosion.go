@@ -0,0 +1,47 @@
+package factory
+
+import (
+	"physengine/components"
+	Vec2 "physengine/helpers/vec2"
+
+	"github.com/yohamta/donburi"
+	"github.com/yohamta/donburi/ecs"
+)
+
+// CreateExplosive creates a bomb at pos that explodes when its fuse runs out
+func CreateExplosive(ecs *ecs.ECS, pos Vec2.Vec2, explosive components.ExplosiveData) *donburi.Entry {
+	entity := ecs.World.Create(components.Explosive, components.Transform)
+	entry := ecs.World.Entry(entity)
+	components.Explosive.SetValue(entry, explosive)
+	tr := components.Transform.Get(entry)
+	tr.Pos = pos
+	tr.Scale = Vec2.Vec2{X: 1, Y: 1}
+	return entry
+}
+
+// CreateExplosionDemo builds two towers of crates either side of a bomb, one of them behind
+// a wall that shelters it from the blast, and a second bomb that later levels the sheltered
+// tower
+func CreateExplosionDemo(ecs *ecs.ECS) {
+	CreateChain(ecs, []Vec2.Vec2{{X: -900, Y: 600}, {X: -900, Y: -300}, {X: 900, Y: -300}, {X: 900, Y: 600}})
+	CreateChainLoop(ecs, []Vec2.Vec2{{X: 150, Y: -300}, {X: 180, Y: -300}, {X: 180, Y: -50}, {X: 150, Y: -50}})
+
+	wood, _ := components.MaterialPreset("wood")
+	for _, x := range []float64{-350, 350} {
+		for row := 0; row < 2; row++ {
+			for col := 0; col < 3; col++ {
+				CreateCompoundBody(ecs, Vec2.Vec2{X: x + float64(col-1)*61, Y: -270 + float64(row)*60}, []components.Shape{
+					{Kind: components.ShapeBox, HalfExtents: Vec2.Vec2{X: 30, Y: 30}},
+				}, wood)
+			}
+		}
+	}
+
+	CreateExplosive(ecs, Vec2.Vec2{X: 0, Y: -280}, components.ExplosiveData{
+		Fuse: 2, Radius: 500, Impulse: 2500, Falloff: components.FalloffLinear, Occluded: true,
+	})
+	// Buried in the base of the tower, where an occluded blast would only reach one crate
+	CreateExplosive(ecs, Vec2.Vec2{X: 350, Y: -290}, components.ExplosiveData{
+		Fuse: 6, Radius: 300, Impulse: 2000, Falloff: components.FalloffQuadratic,
+	})
+}
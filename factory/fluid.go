@@ -0,0 +1,79 @@
+package factory
+
+import (
+	"math"
+	"physengine/components"
+	Vec2 "physengine/helpers/vec2"
+
+	"github.com/yohamta/donburi"
+	"github.com/yohamta/donburi/ecs"
+)
+
+// CreateFluid creates an empty fluid of the given settings whose particles will be spaced
+// half a smoothing radius apart
+func CreateFluid(ecs *ecs.ECS, settings components.FluidSettings) *donburi.Entry {
+	entity := ecs.World.Create(components.Fluid, components.Transform)
+	entry := ecs.World.Entry(entity)
+	spacing := settings.SmoothingRadius / 2
+	components.Fluid.SetValue(entry, components.FluidData{
+		FluidSettings:  settings,
+		ParticleMass:   latticeParticleMass(settings.RestDensity, spacing, settings.SmoothingRadius),
+		ParticleRadius: spacing / 2,
+	})
+	components.Transform.Get(entry).Scale = Vec2.Vec2{X: 1, Y: 1}
+	return entry
+}
+
+// latticeParticleMass returns the particle mass at which a square grid of the given spacing
+// has exactly the rest density, so a freshly poured block neither bursts nor collapses
+func latticeParticleMass(restDensity, spacing, radius float64) float64 {
+	reach := int(math.Ceil(radius / spacing))
+	sum := 0.0
+	for x := -reach; x <= reach; x++ {
+		for y := -reach; y <= reach; y++ {
+			if q := radius*radius - spacing*spacing*float64(x*x+y*y); q > 0 {
+				sum += q * q * q
+			}
+		}
+	}
+	return restDensity / (4 / (math.Pi * math.Pow(radius, 8)) * sum)
+}
+
+// PourFluid fills the box from min to max with particles of the fluid
+func PourFluid(entry *donburi.Entry, min, max Vec2.Vec2) {
+	fluid := components.Fluid.Get(entry)
+	spacing := fluid.SmoothingRadius / 2
+	for y := min.Y + spacing/2; y < max.Y; y += spacing {
+		for x := min.X + spacing/2; x < max.X; x += spacing {
+			fluid.AddParticle(Vec2.Vec2{X: x, Y: y})
+		}
+	}
+	components.Transform.Get(entry).Pos = min.Add(max).Mult(0.5)
+}
+
+// CreateFluidDemo builds a water tank with floating and sinking bodies next to a bin of sand
+// that a heavy ball falls into
+func CreateFluidDemo(ecs *ecs.ECS) {
+	CreateChain(ecs, []Vec2.Vec2{{X: -800, Y: 600}, {X: -800, Y: -400}, {X: 800, Y: -400}, {X: 800, Y: 600}})
+	CreateChainLoop(ecs, []Vec2.Vec2{{X: 190, Y: -400}, {X: 210, Y: -400}, {X: 210, Y: 0}, {X: 190, Y: 0}})
+
+	water, _ := components.FluidPreset("water")
+	PourFluid(CreateFluid(ecs, water), Vec2.Vec2{X: -780, Y: -400}, Vec2.Vec2{X: 180, Y: -180})
+	sand, _ := components.FluidPreset("sand")
+	PourFluid(CreateFluid(ecs, sand), Vec2.Vec2{X: 450, Y: -400}, Vec2.Vec2{X: 780, Y: -150})
+
+	wood, _ := components.MaterialPreset("wood")
+	steel, _ := components.MaterialPreset("steel")
+	CreateCompoundBody(ecs, Vec2.Vec2{X: -500, Y: 0}, []components.Shape{
+		{Kind: components.ShapeBox, HalfExtents: Vec2.Vec2{X: 50, Y: 25}},
+	}, wood)
+	CreateCompoundBody(ecs, Vec2.Vec2{X: -250, Y: 50}, []components.Shape{
+		{Kind: components.ShapeCircle, Radius: 30},
+	}, wood)
+	CreateCompoundBody(ecs, Vec2.Vec2{X: 0, Y: -140}, []components.Shape{
+		{Kind: components.ShapeCircle, Radius: 20},
+	}, steel)
+	CreateCompoundBody(ecs, Vec2.Vec2{X: 600, Y: 300}, []components.Shape{
+		{Kind: components.ShapeCircle, Radius: 35},
+	}, steel)
+}
@@ -0,0 +1,62 @@
+package factory
+
+import (
+	"fmt"
+	"physengine/assets"
+	"physengine/components"
+	Vec2 "physengine/helpers/vec2"
+
+	"github.com/yohamta/donburi"
+	"github.com/yohamta/donburi/ecs"
+)
+
+// MakeBreakable lets an existing body shatter when hit harder than breakable.Strength
+func MakeBreakable(entry *donburi.Entry, breakable components.BreakableData) {
+	if !entry.HasComponent(components.Breakable) {
+		entry.AddComponent(components.Breakable)
+	}
+	components.Breakable.SetValue(entry, breakable)
+}
+
+// addSprite draws an asset over a body at the given scale
+func addSprite(entry *donburi.Entry, key string, scale float64) {
+	entry.AddComponent(components.Sprite)
+	components.Sprite.Get(entry).Image = assets.Image(key)
+	components.Transform.Get(entry).Scale = Vec2.Vec2{X: scale, Y: scale}
+}
+
+// CreateFractureDemo builds a row of breakable crates for a heavy ball to smash through,
+// with a breakable ball and a sprite-traced crescent that shatter on landing
+func CreateFractureDemo(ecs *ecs.ECS) {
+	CreateChain(ecs, []Vec2.Vec2{{X: -900, Y: 600}, {X: -900, Y: -300}, {X: 900, Y: -300}, {X: 900, Y: 600}})
+
+	wood, _ := components.MaterialPreset("wood")
+	crate := components.BreakableData{Strength: 150, Fragments: 4}
+	for col := 0; col < 4; col++ {
+		// Collider matches the sprite at half size
+		entry := CreateCompoundBody(ecs, Vec2.Vec2{X: 100 + float64(col)*63, Y: -252}, []components.Shape{
+			{Kind: components.ShapeBox, HalfExtents: Vec2.Vec2{X: 31, Y: 48}},
+		}, wood)
+		addSprite(entry, "player.png", 0.5)
+		MakeBreakable(entry, crate)
+	}
+
+	tyre, _ := components.MaterialPreset("tyre")
+	ball := CreateCompoundBody(ecs, Vec2.Vec2{X: -600, Y: -200}, []components.Shape{
+		{Kind: components.ShapeCircle, Radius: 30},
+	}, tyre)
+	components.Velocity.Get(ball).Velocity = Vec2.Vec2{X: 700, Y: 150}
+
+	ice, _ := components.MaterialPreset("ice")
+	orb := CreateCompoundBody(ecs, Vec2.Vec2{X: -300, Y: -100}, []components.Shape{
+		{Kind: components.ShapeCircle, Radius: 47},
+	}, ice)
+	addSprite(orb, "enemy.png", 0.5)
+	MakeBreakable(orb, components.BreakableData{Strength: 100, Fragments: 6})
+
+	if entry, err := CreateSpriteBody(ecs, Vec2.Vec2{X: 600, Y: 0}, "crescent.png", 2, ice); err != nil {
+		fmt.Println(err)
+	} else {
+		MakeBreakable(entry, components.BreakableData{Strength: 100, Fragments: 6})
+	}
+}
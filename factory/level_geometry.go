@@ -0,0 +1,73 @@
+package factory
+
+import (
+	"physengine/components"
+	Vec2 "physengine/helpers/vec2"
+
+	"github.com/yohamta/donburi"
+	"github.com/yohamta/donburi/ecs"
+)
+
+// createStaticBody creates level geometry at pos with the given collider component
+func createStaticBody(ecs *ecs.ECS, pos Vec2.Vec2, collider donburi.IComponentType) *donburi.Entry {
+	return createKinematicBody(ecs, pos, collider, components.StaticBody)
+}
+
+// createKinematicBody creates a body with infinite mass that collisions cannot move
+func createKinematicBody(ecs *ecs.ECS, pos Vec2.Vec2, comps ...donburi.IComponentType) *donburi.Entry {
+	comps = append(comps, components.Transform, components.MaterialComponent, components.MassComponent, components.Velocity, components.AngularVelocity)
+	entity := ecs.World.Create(comps...)
+	entry := ecs.World.Entry(entity)
+	components.SetPos(entry, pos)
+	components.Transform.Get(entry).Scale = Vec2.Vec2{X: 1, Y: 1}
+	steel, _ := components.MaterialPreset("steel")
+	components.MaterialComponent.SetValue(entry, steel)
+	return entry
+}
+
+// CreateChain creates static ground from a polyline in world space. The solid side is on
+// the right of the point order, so list ground from left to right.
+func CreateChain(ecs *ecs.ECS, points []Vec2.Vec2) *donburi.Entry {
+	entry := createStaticBody(ecs, Vec2.Vec2{}, components.ChainCollider)
+	components.ChainCollider.Get(entry).Points = append([]Vec2.Vec2(nil), points...)
+	return entry
+}
+
+// CreateChainLoop creates a static closed outline in world space that is solid inside,
+// whichever way the points wind
+func CreateChainLoop(ecs *ecs.ECS, points []Vec2.Vec2) *donburi.Entry {
+	entry := createStaticBody(ecs, Vec2.Vec2{}, components.ChainCollider)
+	chain := components.ChainCollider.Get(entry)
+	chain.Points = components.ClockwiseLoop(append([]Vec2.Vec2(nil), points...))
+	chain.Loop = true
+	return entry
+}
+
+// CreateTilemap creates static geometry from rows of text where '#' is a solid tile.
+// topLeft is the world position of the map's top-left corner.
+func CreateTilemap(ecs *ecs.ECS, topLeft Vec2.Vec2, rows []string, tileSize float64, mode components.TilemapMode) *donburi.Entry {
+	entry := createStaticBody(ecs, topLeft, components.TilemapCollider)
+	components.TilemapCollider.SetValue(entry, components.TilemapColliderData{
+		Tiles:    components.ParseTiles(rows),
+		TileSize: tileSize,
+		Mode:     mode,
+	})
+	return entry
+}
+
+// CreateLevelGeometryDemo builds rolling ground, a floating rock and a small tile structure
+func CreateLevelGeometryDemo(ecs *ecs.ECS) {
+	CreateChain(ecs, []Vec2.Vec2{
+		{X: -1400, Y: -650}, {X: -1000, Y: -700}, {X: -600, Y: -800}, {X: -200, Y: -820},
+		{X: 200, Y: -780}, {X: 600, Y: -800}, {X: 1000, Y: -700}, {X: 1400, Y: -600},
+	})
+	CreateChainLoop(ecs, []Vec2.Vec2{
+		{X: -1000, Y: 500}, {X: -900, Y: 420}, {X: -780, Y: 460}, {X: -800, Y: 560}, {X: -930, Y: 580},
+	})
+	CreateTilemap(ecs, Vec2.Vec2{X: 800, Y: 700}, []string{
+		"#........#",
+		"#........#",
+		"#...##...#",
+		"##########",
+	}, 40, components.TilemapChains)
+}
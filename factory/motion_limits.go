@@ -0,0 +1,15 @@
+package factory
+
+import (
+	"physengine/components"
+
+	"github.com/yohamta/donburi"
+)
+
+// LimitMotion caps the speeds of an existing body and locks the axes or rotation it may not use
+func LimitMotion(entry *donburi.Entry, limits components.MotionLimitsData) {
+	if !entry.HasComponent(components.MotionLimits) {
+		entry.AddComponent(components.MotionLimits)
+	}
+	components.MotionLimits.SetValue(entry, limits)
+}
@@ -0,0 +1,85 @@
+package factory
+
+import (
+	"math"
+	"math/rand"
+	"physengine/components"
+	Vec2 "physengine/helpers/vec2"
+
+	"github.com/yohamta/donburi"
+	"github.com/yohamta/donburi/ecs"
+)
+
+// CreatePlanet creates a round dynamic body that pulls every other body with its own mass
+func CreatePlanet(ecs *ecs.ECS, pos Vec2.Vec2, radius float64, material components.MaterialData, softening float64) *donburi.Entry {
+	entry := CreateCompoundBody(ecs, pos, []components.Shape{{Kind: components.ShapeCircle, Radius: radius}}, material)
+	entry.AddComponent(components.Attractor)
+	components.Attractor.SetValue(entry, components.AttractorData{Softening: softening})
+	return entry
+}
+
+// OrbitAround sets a body moving anticlockwise on a circular orbit around an attractor at
+// its current distance, with the speed at which the attractor's softened pull turns it
+func OrbitAround(w donburi.World, body, center *donburi.Entry) {
+	world := components.GetPhysicsWorld(w)
+	if world == nil || !center.HasComponent(components.Attractor) {
+		return
+	}
+	mass := components.Attractor.Get(center).Mass
+	if mass <= 0 {
+		mass = components.MassComponent.Get(center).Mass
+	}
+	softening := components.Attractor.Get(center).Softening
+	if body.HasComponent(components.Attractor) {
+		// Both softening lengths count, as in the integrator
+		softening = math.Hypot(softening, components.Attractor.Get(body).Softening)
+	}
+
+	r := components.Transform.Get(body).Pos.Add(components.Transform.Get(center).Pos.Mult(-1))
+	d := r.Magnitude()
+	if d == 0 {
+		return
+	}
+	// v²/d = G·M·d / (d² + ε²)^(3/2)
+	speed := d * math.Sqrt(world.GravitationalConstant*mass/math.Pow(d*d+softening*softening, 1.5))
+	tangent := Vec2.Vec2{X: -r.Y / d, Y: r.X / d}
+	components.Velocity.Get(body).Velocity = components.Velocity.Get(center).Velocity.Add(tangent.Mult(speed))
+}
+
+// CreateOrbitDemo builds a small solar system: a planet close to the sun, a belt of
+// asteroids that pull on each other through the quadtree, and a heavy outer planet with a
+// moon. The sun drifts so that the total momentum is zero and the system stays in view.
+func CreateOrbitDemo(ecs *ecs.ECS) {
+	rock, _ := components.MaterialPreset("steel")
+	ice, _ := components.MaterialPreset("ice")
+	// Dense cores keep the sun far heavier than everything else and let the outer
+	// planet hold on to its moon
+	star, core := rock, rock
+	star.Density, core.Density = 0.5, 0.05
+
+	sun := CreatePlanet(ecs, Vec2.Vec2{}, 50, star, 25)
+	inner := CreatePlanet(ecs, Vec2.Vec2{X: 180}, 10, rock, 5)
+	OrbitAround(ecs.World, inner, sun)
+	outer := CreatePlanet(ecs, Vec2.Vec2{X: -650}, 18, core, 9)
+	OrbitAround(ecs.World, outer, sun)
+
+	// The moon circles the outer planet, so it also follows the planet around the sun
+	moon := CreatePlanet(ecs, Vec2.Vec2{X: -690}, 5, rock, 2)
+	OrbitAround(ecs.World, moon, outer)
+
+	bodies := []*donburi.Entry{inner, outer, moon}
+	for i := 0; i < 150; i++ {
+		angle := rand.Float64() * 2 * math.Pi
+		dist := 290 + rand.Float64()*60
+		radius := 2 + rand.Float64()*3
+		asteroid := CreatePlanet(ecs, Vec2.Vec2{X: math.Cos(angle) * dist, Y: math.Sin(angle) * dist}, radius, ice, 2)
+		OrbitAround(ecs.World, asteroid, sun)
+		bodies = append(bodies, asteroid)
+	}
+
+	momentum := Vec2.Vec2{}
+	for _, body := range bodies {
+		momentum.AddUpdate(components.Velocity.Get(body).Velocity.Mult(components.MassComponent.Get(body).Mass))
+	}
+	components.Velocity.Get(sun).Velocity = momentum.Mult(-1 / components.MassComponent.Get(sun).Mass)
+}
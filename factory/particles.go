@@ -0,0 +1,119 @@
+package factory
+
+import (
+	"image/color"
+	"math"
+	"physengine/components"
+	Vec2 "physengine/helpers/vec2"
+
+	"github.com/yohamta/donburi"
+	"github.com/yohamta/donburi/ecs"
+)
+
+// CreateParticleEmitter creates a free-standing emitter at pos
+func CreateParticleEmitter(ecs *ecs.ECS, pos Vec2.Vec2, emitter components.ParticleEmitterData) *donburi.Entry {
+	entity := ecs.World.Create(components.Transform, components.ParticleEmitter)
+	entry := ecs.World.Entry(entity)
+	tr := components.Transform.Get(entry)
+	tr.Pos = pos
+	tr.Scale = Vec2.Vec2{X: 1, Y: 1}
+	components.ParticleEmitter.SetValue(entry, emitter)
+	return entry
+}
+
+// AttachParticleEmitter adds an emitter to an existing body so it emits from the body as it moves
+func AttachParticleEmitter(entry *donburi.Entry, emitter components.ParticleEmitterData) {
+	if !entry.HasComponent(components.ParticleEmitter) {
+		entry.AddComponent(components.ParticleEmitter)
+	}
+	components.ParticleEmitter.SetValue(entry, emitter)
+}
+
+// CreateContactSparks creates the emitter that turns hard collisions into showers of sparks
+func CreateContactSparks(ecs *ecs.ECS) *donburi.Entry {
+	entity := ecs.World.Create(components.ParticleEmitter, components.ContactSparks)
+	entry := ecs.World.Entry(entity)
+	components.ParticleEmitter.SetValue(entry, components.ParticleEmitterData{
+		Lifetime:       0.3,
+		LifetimeJitter: 0.4,
+		Speed:          120,
+		SpeedJitter:    180,
+		Spread:         math.Pi / 3,
+		Colors: []components.ColorKey{
+			{T: 0, Color: color.RGBA{R: 255, G: 255, B: 220, A: 255}},
+			{T: 0.4, Color: color.RGBA{R: 255, G: 190, B: 60, A: 255}},
+			{T: 1, Color: color.RGBA{R: 0, G: 0, B: 0, A: 0}},
+		},
+		Sizes:        []components.SizeKey{{T: 0, Size: 3}, {T: 1, Size: 1}},
+		GravityScale: 0.6,
+		Drag:         1,
+		Collide:      true,
+		Restitution:  0.4,
+		Friction:     0.3,
+		MaxParticles: 3000,
+	})
+	return entry
+}
+
+// CreateParticleDemo builds a walled box with a fountain splashing on a ramp, smoke trailing
+// from a rolling ball, and steel blocks that throw sparks when they land
+func CreateParticleDemo(ecs *ecs.ECS) {
+	CreateChain(ecs, []Vec2.Vec2{{X: -800, Y: 600}, {X: -800, Y: -400}, {X: 800, Y: -400}, {X: 800, Y: 600}})
+	CreateRamp(ecs, Vec2.Vec2{X: -750, Y: 100}, Vec2.Vec2{X: -250, Y: -150}, false)
+	CreateContactSparks(ecs)
+
+	// Water fountain arcing onto the ramp
+	CreateParticleEmitter(ecs, Vec2.Vec2{X: -150, Y: -390}, components.ParticleEmitterData{
+		Emitting:       true,
+		Rate:           300,
+		Lifetime:       2,
+		LifetimeJitter: 0.5,
+		Speed:          650,
+		SpeedJitter:    80,
+		Direction:      math.Pi * 0.62,
+		Spread:         0.15,
+		Colors: []components.ColorKey{
+			{T: 0, Color: color.RGBA{R: 150, G: 200, B: 255, A: 255}},
+			{T: 0.8, Color: color.RGBA{R: 50, G: 100, B: 190, A: 200}},
+			{T: 1, Color: color.RGBA{R: 0, G: 0, B: 0, A: 0}},
+		},
+		Sizes:        []components.SizeKey{{T: 0, Size: 3}, {T: 1, Size: 5}},
+		GravityScale: 1,
+		Drag:         0.2,
+		Collide:      true,
+		Restitution:  0.2,
+		Friction:     0.1,
+		MaxParticles: 1500,
+	})
+
+	// Ball rolling down the ramp, trailing smoke that rises and spreads
+	wood, _ := components.MaterialPreset("wood")
+	steel, _ := components.MaterialPreset("steel")
+	ball := CreateCompoundBody(ecs, Vec2.Vec2{X: -700, Y: 200}, []components.Shape{
+		{Kind: components.ShapeCircle, Radius: 30},
+	}, wood)
+	AttachParticleEmitter(ball, components.ParticleEmitterData{
+		Emitting:       true,
+		Rate:           60,
+		Lifetime:       1.5,
+		LifetimeJitter: 0.5,
+		Speed:          20,
+		SpeedJitter:    20,
+		Spread:         2 * math.Pi,
+		Colors: []components.ColorKey{
+			{T: 0, Color: color.RGBA{R: 120, G: 120, B: 120, A: 160}},
+			{T: 1, Color: color.RGBA{R: 0, G: 0, B: 0, A: 0}},
+		},
+		Sizes:        []components.SizeKey{{T: 0, Size: 6}, {T: 1, Size: 24}},
+		GravityScale: -0.1,
+		Drag:         0.5,
+		MaxParticles: 500,
+	})
+
+	// Steel blocks dropped from different heights land with different impulses
+	for i, height := range []float64{0, 200, 450} {
+		CreateCompoundBody(ecs, Vec2.Vec2{X: 200 + float64(i)*180, Y: height}, []components.Shape{
+			{Kind: components.ShapeBox, HalfExtents: Vec2.Vec2{X: 30, Y: 30}},
+		}, steel)
+	}
+}
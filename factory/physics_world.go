@@ -0,0 +1,22 @@
+package factory
+
+import (
+	"physengine/components"
+
+	"github.com/yohamta/donburi"
+	"github.com/yohamta/donburi/ecs"
+)
+
+// CreatePhysicsWorld creates the world settings entity with light default damping and the
+// energy statistics and step clock of the integrator, and the step telemetry
+func CreatePhysicsWorld(ecs *ecs.ECS) *donburi.Entry {
+	entity := ecs.World.Create(components.PhysicsWorld, components.IntegrationStats, components.PhysicsClock, components.Telemetry)
+	entry := ecs.World.Entry(entity)
+	components.PhysicsWorld.SetValue(entry, components.PhysicsWorldData{
+		DefaultLinearDamping:  0.05,
+		DefaultAngularDamping: 0.1,
+		AirDensity:            0.0001,
+		BarnesHutTheta:        0.5,
+	})
+	return entry
+}
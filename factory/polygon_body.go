@@ -0,0 +1,70 @@
+package factory
+
+import (
+	"fmt"
+	"physengine/assets"
+	"physengine/components"
+	Vec2 "physengine/helpers/vec2"
+
+	"github.com/yohamta/donburi"
+	"github.com/yohamta/donburi/ecs"
+)
+
+// outlineAlphaThreshold is the alpha above which a sprite pixel counts as solid
+const outlineAlphaThreshold = 128
+
+// CreatePolygonBody creates a body from a simple polygon outline in body space, which may be
+// concave. The outline is split into convex pieces that share one compound body.
+func CreatePolygonBody(ecs *ecs.ECS, pos Vec2.Vec2, outline []Vec2.Vec2, material components.MaterialData) (*donburi.Entry, error) {
+	shapes, err := components.PolygonShapes(outline)
+	if err != nil {
+		return nil, err
+	}
+	return CreateCompoundBody(ecs, pos, shapes, material), nil
+}
+
+// CreateSpriteBody creates a body whose collider is traced from the alpha channel of a
+// sprite asset. tolerance is how far, in pixels, the collider may stray from the outline.
+func CreateSpriteBody(ecs *ecs.ECS, pos Vec2.Vec2, key string, tolerance float64, material components.MaterialData) (*donburi.Entry, error) {
+	img, err := assets.ImageData(key)
+	if err != nil {
+		return nil, err
+	}
+	outline, err := components.TraceAlphaOutline(img, outlineAlphaThreshold, tolerance)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", key, err)
+	}
+	entry, err := CreatePolygonBody(ecs, pos, outline, material)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", key, err)
+	}
+
+	// The body was moved to its centre of mass, so pin the sprite to that point of the image
+	centroid := components.Transform.Get(entry).Pos.Add(pos.Mult(-1))
+	entry.AddComponent(components.Sprite)
+	sprite := components.Sprite.Get(entry)
+	sprite.Image = assets.Image(key)
+	sprite.PivotOffset = Vec2.Vec2{X: centroid.X, Y: -centroid.Y}
+	return entry, nil
+}
+
+// CreatePolygonDemo drops a concave polygon and a sprite-traced crescent
+func CreatePolygonDemo(ecs *ecs.ECS) {
+	wood, _ := components.MaterialPreset("wood")
+	star := []Vec2.Vec2{
+		{X: 0, Y: 90}, {X: 25, Y: 30}, {X: 90, Y: 25}, {X: 40, Y: -15}, {X: 55, Y: -80},
+		{X: 0, Y: -40}, {X: -55, Y: -80}, {X: -40, Y: -15}, {X: -90, Y: 25}, {X: -25, Y: 30},
+	}
+	if entry, err := CreatePolygonBody(ecs, Vec2.Vec2{X: 400, Y: -200}, star, wood); err != nil {
+		fmt.Println(err)
+	} else {
+		components.Velocity.Get(entry).Velocity = Vec2.Vec2{X: -40, Y: -60}
+		components.SetAngularVelocity(entry, 0.8)
+	}
+
+	if entry, err := CreateSpriteBody(ecs, Vec2.Vec2{X: -300, Y: -250}, "crescent.png", 2, wood); err != nil {
+		fmt.Println(err)
+	} else {
+		components.Velocity.Get(entry).Velocity = Vec2.Vec2{X: 30, Y: -80}
+	}
+}
@@ -0,0 +1,389 @@
+package factory
+
+import (
+	"encoding/json"
+	"fmt"
+	"physengine/assets"
+	"physengine/components"
+	Vec2 "physengine/helpers/vec2"
+
+	"github.com/yohamta/donburi"
+	"github.com/yohamta/donburi/ecs"
+)
+
+// Prefab shape types
+const (
+	ShapeCircle   = "circle"
+	ShapeBox      = "box"
+	ShapeCapsule  = "capsule"
+	ShapeSegment  = "segment"
+	ShapePolygon  = "polygon"
+	ShapeCompound = "compound"
+)
+
+// PrefabShape describes the collider of a prefab
+type PrefabShape struct {
+	Type    string        `json:"type"`
+	Radius  float64       `json:"radius,omitempty"` // circle
+	Min     Vec2.Vec2     `json:"min"`              // box, relative to the body position
+	Max     Vec2.Vec2     `json:"max"`
+	Offset  Vec2.Vec2     `json:"offset"`            // circle centre relative to the body position
+	Rot     float64       `json:"rot,omitempty"`     // local rotation of a box
+	Density float64       `json:"density,omitempty"` // compound part density, 0 uses the material
+	Parts   []PrefabShape `json:"parts,omitempty"`   // compound
+
+	HalfLength float64   `json:"halfLength,omitempty"` // capsule, also uses radius, offset and rot
+	A          Vec2.Vec2 `json:"a"`                    // segment end points, also uses radius
+	B          Vec2.Vec2 `json:"b"`
+	OneSided   bool      `json:"oneSided,omitempty"`
+
+	Points []Vec2.Vec2 `json:"points,omitempty"` // polygon outline, may be concave
+}
+
+// toShape converts a single shape description to a body-space shape
+func (ps PrefabShape) toShape() components.Shape {
+	var shape components.Shape
+	switch ps.Type {
+	case ShapeBox:
+		box := ps.box()
+		shape = box.Shape()
+	case ShapeCapsule:
+		capsule := ps.capsule()
+		shape = capsule.Shape()
+	case ShapeSegment:
+		segment := ps.segment()
+		shape = segment.Shape()
+	case ShapeCircle:
+		shape = components.Shape{Kind: components.ShapeCircle, Offset: ps.Offset, Radius: ps.Radius}
+	}
+	shape.Density = ps.Density
+	return shape
+}
+
+func (ps PrefabShape) box() components.AABB_Data {
+	return components.AABB_Data{Min: ps.Min, Max: ps.Max, Rot: ps.Rot}
+}
+
+func (ps PrefabShape) capsule() components.CapsuleColliderData {
+	return components.CapsuleColliderData{Radius: ps.Radius, HalfLength: ps.HalfLength, Offset: ps.Offset, Rot: ps.Rot}
+}
+
+func (ps PrefabShape) segment() components.SegmentColliderData {
+	return components.SegmentColliderData{A: ps.A, B: ps.B, Radius: ps.Radius, OneSided: ps.OneSided}
+}
+
+// Prefab is a declarative body template used by Spawn
+type Prefab struct {
+	Name            string                   `json:"name"`
+	Shape           PrefabShape              `json:"shape"`
+	Mass            float64                  `json:"mass,omitempty"`    // 0 means density * area
+	Inertia         float64                  `json:"inertia,omitempty"` // 0 means computed from the shape
+	Static          bool                     `json:"static,omitempty"`  // Infinite mass and inertia, cached by the broadphase
+	Material        string                   `json:"material,omitempty"`
+	MaterialData    *components.MaterialData `json:"materialData,omitempty"` // Replaces the preset when set
+	Sprite          string                   `json:"sprite,omitempty"`       // Asset key
+	Scale           Vec2.Vec2                `json:"scale"`
+	Velocity        Vec2.Vec2                `json:"velocity"`
+	AngularVelocity float64                  `json:"angularVelocity,omitempty"`
+	Torque          float64                  `json:"torque,omitempty"`
+	Draggable       bool                     `json:"draggable,omitempty"`
+	Damping         *components.DampingData  `json:"damping,omitempty"` // Replaces the world defaults when set
+	AirDrag         float64                  `json:"airDrag,omitempty"` // Drag coefficient, 0 disables air drag
+}
+
+// PrefabOverrides replaces prefab values for a single spawn; nil fields keep the prefab value
+type PrefabOverrides struct {
+	Velocity        *Vec2.Vec2
+	AngularVelocity *float64
+	Rotation        *float64
+	Torque          *float64
+	Mass            *float64
+	Material        *string
+	Sprite          *string
+}
+
+var prefabs = map[string]Prefab{}
+
+// RegisterPrefab makes a prefab available to SpawnNamed
+func RegisterPrefab(prefab Prefab) {
+	prefabs[prefab.Name] = prefab
+}
+
+// GetPrefab returns a registered prefab
+func GetPrefab(name string) (Prefab, bool) {
+	prefab, ok := prefabs[name]
+	return prefab, ok
+}
+
+// ParsePrefabs decodes a JSON array of prefabs
+func ParsePrefabs(data []byte) ([]Prefab, error) {
+	var list []Prefab
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("failed to parse prefabs: %w", err)
+	}
+	for _, prefab := range list {
+		if prefab.Name == "" {
+			return nil, fmt.Errorf("failed to parse prefabs: prefab without a name")
+		}
+		if err := prefab.validate(); err != nil {
+			return nil, fmt.Errorf("failed to parse prefabs: prefab %q: %w", prefab.Name, err)
+		}
+	}
+	return list, nil
+}
+
+// validate reports shape types and material names a prefab does not know, and polygon
+// outlines that cannot be decomposed
+func (p Prefab) validate() error {
+	if err := p.Shape.validate(true); err != nil {
+		return err
+	}
+	if p.MaterialData == nil {
+		return validateMaterial(p.Material)
+	}
+	return nil
+}
+
+// validate checks the shape type, and the parts of a compound; compound parts must be
+// simple shapes
+func (ps PrefabShape) validate(top bool) error {
+	switch ps.Type {
+	case ShapeCircle, ShapeBox, ShapeCapsule, ShapeSegment:
+		return nil
+	case ShapePolygon:
+		if !top {
+			break
+		}
+		_, err := components.PolygonShapes(ps.Points)
+		return err
+	case ShapeCompound:
+		if !top {
+			break
+		}
+		for _, part := range ps.Parts {
+			if err := part.validate(false); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown shape type %q", ps.Type)
+	}
+	return fmt.Errorf("shape type %q cannot be a compound part", ps.Type)
+}
+
+// validateMaterial reports a material name that is not a preset; no name means no material
+func validateMaterial(name string) error {
+	if name == "" {
+		return nil
+	}
+	if _, ok := components.MaterialPreset(name); !ok {
+		return fmt.Errorf("unknown material %q", name)
+	}
+	return nil
+}
+
+// LoadPrefabs reads a prefab file from the asset manager and registers every prefab in it.
+// A prefab with an unknown shape type or material name fails the whole file.
+func LoadPrefabs(key string) error {
+	data, err := assets.ReadFile(key)
+	if err != nil {
+		return err
+	}
+	list, err := ParsePrefabs(data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", key, err)
+	}
+	for _, prefab := range list {
+		RegisterPrefab(prefab)
+	}
+	return nil
+}
+
+// SpawnNamed spawns a registered prefab
+func SpawnNamed(ecs *ecs.ECS, name string, pos Vec2.Vec2, overrides *PrefabOverrides) (*donburi.Entry, error) {
+	prefab, ok := GetPrefab(name)
+	if !ok {
+		return nil, fmt.Errorf("unknown prefab %q", name)
+	}
+	return Spawn(ecs, prefab, pos, overrides)
+}
+
+// Spawn creates a physics body from a prefab at pos. A prefab, with its overrides, that has an
+// unknown shape type or material name, or a polygon outline that cannot be decomposed, is an
+// error and creates nothing.
+func Spawn(ecs *ecs.ECS, prefab Prefab, pos Vec2.Vec2, overrides *PrefabOverrides) (*donburi.Entry, error) {
+	prefab = prefab.withOverrides(overrides)
+	if err := prefab.validate(); err != nil {
+		return nil, fmt.Errorf("prefab %q: %w", prefab.Name, err)
+	}
+	var polygon []components.Shape
+	if prefab.Shape.Type == ShapePolygon {
+		shapes, err := components.PolygonShapes(prefab.Shape.Points)
+		if err != nil {
+			return nil, fmt.Errorf("prefab %q: %w", prefab.Name, err)
+		}
+		polygon = shapes
+	}
+
+	var collider donburi.IComponentType
+	switch prefab.Shape.Type {
+	case ShapeCircle:
+		collider = components.CircleCollider
+	case ShapeBox:
+		collider = components.AABB_Component
+	case ShapeCapsule:
+		collider = components.CapsuleCollider
+	case ShapeSegment:
+		collider = components.SegmentCollider
+	case ShapeCompound, ShapePolygon:
+		collider = components.CompoundCollider
+	}
+	comps := []donburi.IComponentType{components.MaterialComponent, components.Transform, collider, components.Drawable, components.MassComponent, components.Velocity, components.AngularVelocity, components.Torque}
+	if prefab.Draggable {
+		comps = append(comps, components.Draggable)
+	}
+	if prefab.Damping != nil {
+		comps = append(comps, components.Damping)
+	}
+	if prefab.AirDrag > 0 {
+		comps = append(comps, components.AirDrag)
+	}
+	if prefab.Static {
+		comps = append(comps, components.StaticBody)
+	}
+
+	entity := ecs.World.Create(comps...)
+	entry := ecs.World.Entry(entity)
+	components.SetPos(entry, pos)
+	components.Velocity.Get(entry).Velocity = prefab.Velocity
+	components.SetAngularVelocity(entry, prefab.AngularVelocity)
+	components.SetTorque(entry, prefab.Torque)
+	if overrides != nil && overrides.Rotation != nil {
+		components.SetRot(entry, *overrides.Rotation)
+	}
+	if prefab.Damping != nil {
+		components.Damping.SetValue(entry, *prefab.Damping)
+	}
+	if prefab.AirDrag > 0 {
+		components.AirDrag.Get(entry).Coefficient = prefab.AirDrag
+	}
+
+	if prefab.Sprite != "" {
+		components.Drawable.Get(entry).Sprite = assets.Image(prefab.Sprite)
+	}
+	scale := prefab.Scale
+	if scale.X == 0 && scale.Y == 0 {
+		scale = Vec2.Vec2{X: 1, Y: 1}
+	}
+	components.Transform.Get(entry).Scale = scale
+
+	mat := prefab.material()
+	components.MaterialComponent.SetValue(entry, mat)
+
+	switch prefab.Shape.Type {
+	case ShapeBox:
+		components.AABB_Component.SetValue(entry, prefab.Shape.box())
+	case ShapeCapsule:
+		components.CapsuleCollider.SetValue(entry, prefab.Shape.capsule())
+	case ShapeSegment:
+		components.SegmentCollider.SetValue(entry, prefab.Shape.segment())
+	case ShapePolygon:
+		components.CompoundCollider.Get(entry).Shapes = polygon
+	case ShapeCompound:
+		compound := components.CompoundCollider.Get(entry)
+		for _, part := range prefab.Shape.Parts {
+			compound.Shapes = append(compound.Shapes, part.toShape())
+		}
+	case ShapeCircle:
+		crcl := components.CircleCollider.Get(entry)
+		crcl.Radius = prefab.Shape.Radius
+		crcl.Offset = prefab.Shape.Offset
+	}
+
+	if !prefab.Static {
+		setPrefabMass(entry, prefab, mat)
+	}
+
+	return entry, nil
+}
+
+// mustSpawn spawns one of the built-in prefabs, which are always valid
+func mustSpawn(ecs *ecs.ECS, prefab Prefab, pos Vec2.Vec2, overrides *PrefabOverrides) *donburi.Entry {
+	entry, err := Spawn(ecs, prefab, pos, overrides)
+	if err != nil {
+		panic(err)
+	}
+	return entry
+}
+
+// setPrefabMass moves the body position to the centre of mass of its shapes and derives mass
+// and inertia from the shapes and material density.
+// An explicit Mass keeps the shape's mass distribution, an explicit Inertia wins outright.
+func setPrefabMass(entry *donburi.Entry, prefab Prefab, mat components.MaterialData) {
+	density := mat.Density
+	if density <= 0 {
+		if prefab.Mass <= 0 {
+			return
+		}
+		density = 1
+	}
+	// Any shape may sit off the body position, and impulses and rotation act about the
+	// position, so it has to be the centre of mass
+	components.RecenterBody(entry, density)
+	components.SetMassFromShapes(entry, density)
+
+	mc := components.MassComponent.Get(entry)
+	if prefab.Mass > 0 && mc.Mass > 0 {
+		mc.Inertia *= prefab.Mass / mc.Mass
+		mc.Mass = prefab.Mass
+	}
+	if prefab.Inertia > 0 {
+		mc.Inertia = prefab.Inertia
+	}
+	mc.InverseMass = 0
+	mc.InverseInertia = 0
+	if mc.Mass > 0 {
+		mc.InverseMass = 1 / mc.Mass
+	}
+	if mc.Inertia > 0 {
+		mc.InverseInertia = 1 / mc.Inertia
+	}
+}
+
+func (p Prefab) withOverrides(o *PrefabOverrides) Prefab {
+	if o == nil {
+		return p
+	}
+	if o.Velocity != nil {
+		p.Velocity = *o.Velocity
+	}
+	if o.AngularVelocity != nil {
+		p.AngularVelocity = *o.AngularVelocity
+	}
+	if o.Torque != nil {
+		p.Torque = *o.Torque
+	}
+	if o.Mass != nil {
+		p.Mass = *o.Mass
+		p.Inertia = 0
+	}
+	if o.Material != nil {
+		p.Material = *o.Material
+		p.MaterialData = nil
+	}
+	if o.Sprite != nil {
+		p.Sprite = *o.Sprite
+	}
+	return p
+}
+
+func (p Prefab) material() components.MaterialData {
+	if p.MaterialData != nil {
+		return *p.MaterialData
+	}
+	if mat, ok := components.MaterialPreset(p.Material); ok {
+		return mat
+	}
+	return components.MaterialData{}
+}
@@ -0,0 +1,222 @@
+package factory
+
+import (
+	"image/color"
+	"math"
+	"physengine/components"
+	Vec2 "physengine/helpers/vec2"
+
+	"github.com/yohamta/donburi"
+	"github.com/yohamta/donburi/ecs"
+)
+
+// RopeLink selects how the links of a rope are joined
+type RopeLink int
+
+const (
+	RopeDistance RopeLink = iota // Beads held by rope joints that can go slack; light and floppy
+	RopeRevolute                 // Capsules pinned end to end; stiffer, for chains and bridges
+)
+
+// RopeAnchor is one end of a rope: a point on Body, or a fixed world point when Body is nil
+type RopeAnchor struct {
+	Body  *donburi.Entry
+	Point Vec2.Vec2 // World position of the attachment
+}
+
+// RopeSettings configures CreateRope
+type RopeSettings struct {
+	Segments  int
+	Length    float64 // Total length; 0 or anything shorter than the gap uses the distance between the anchors
+	Mass      float64 // Total mass of the links
+	Thickness float64
+	Link      RopeLink
+	TearForce float64 // Joints break above this force, 0 never tears
+	Color     color.RGBA
+}
+
+// CreateRope hangs a rope of small bodies between two anchors. Extra length sags below the
+// line between the anchors. It returns the rope entity, which holds the links and joints.
+func CreateRope(ecs *ecs.ECS, start, end RopeAnchor, settings RopeSettings) *donburi.Entry {
+	segments := max(settings.Segments, 1)
+	thickness := settings.Thickness
+	if thickness <= 0 {
+		thickness = 6
+	}
+	points := ropePoints(start.Point, end.Point, settings.Length, segments)
+	segmentLength := math.Max(settings.Length, Vec2.Distance(start.Point, end.Point)) / float64(segments)
+
+	rope := components.RopeData{Thickness: thickness, Color: settings.Color}
+	if rope.Color.A == 0 {
+		rope.Color = color.RGBA{R: 190, G: 150, B: 90, A: 255}
+	}
+	startBody := ropeAnchorBody(ecs, start)
+	endBody := ropeAnchorBody(ecs, end)
+
+	wood, _ := components.MaterialPreset("wood")
+	radius := thickness / 2
+	switch settings.Link {
+	case RopeRevolute:
+		linkMass := settings.Mass / float64(segments)
+		halfLength := segmentLength / 2
+		wood.Density = linkMass / (4*halfLength*radius + math.Pi*radius*radius)
+		for i := 0; i < segments; i++ {
+			a, b := points[i], points[i+1]
+			d := b.Add(a.Mult(-1))
+			link := CreateCompoundBody(ecs, a.Add(b).Mult(0.5), []components.Shape{
+				{Kind: components.ShapeCapsule, Radius: radius, HalfLength: halfLength},
+			}, wood)
+			// Capsules lie along their local Y axis
+			components.Transform.Get(link).Rot = math.Atan2(d.Y, d.X) - math.Pi/2
+			rope.Links = append(rope.Links, link)
+		}
+		previous := startBody
+		for i, p := range points {
+			next := endBody
+			if i < segments {
+				next = rope.Links[i]
+			}
+			rope.Joints = append(rope.Joints, createRevoluteJoint(ecs, previous, next, p, settings.TearForce))
+			previous = next
+		}
+	default:
+		linkMass := settings.Mass / float64(max(segments-1, 1))
+		wood.Density = linkMass / (math.Pi * radius * radius)
+		for _, p := range points[1:segments] {
+			rope.Links = append(rope.Links, CreateCompoundBody(ecs, p, []components.Shape{
+				{Kind: components.ShapeCircle, Radius: radius},
+			}, wood))
+		}
+		bodies := append(append([]*donburi.Entry{startBody}, rope.Links...), endBody)
+		for i := 0; i < segments; i++ {
+			rope.Joints = append(rope.Joints, createDistanceJoint(ecs, bodies[i], bodies[i+1], points[i], points[i+1], segmentLength, settings.TearForce))
+		}
+	}
+
+	if start.Body != nil || end.Body != nil {
+		rope.Limit = createDistanceJoint(ecs, startBody, endBody, start.Point, end.Point, segmentLength*float64(segments), settings.TearForce)
+		components.Joint.Get(rope.Limit).CollideConnected = true
+	}
+
+	entity := ecs.World.Create(components.Rope)
+	entry := ecs.World.Entry(entity)
+	components.Rope.SetValue(entry, rope)
+	return entry
+}
+
+// ropeAnchorBody returns the body a rope end attaches to, creating a fixed one for world points
+func ropeAnchorBody(ecs *ecs.ECS, anchor RopeAnchor) *donburi.Entry {
+	if anchor.Body != nil {
+		return anchor.Body
+	}
+	return createKinematicBody(ecs, anchor.Point, components.StaticBody)
+}
+
+// ropePoints returns segments+1 points spaced evenly along a parabola from a to b whose
+// length is about length, sagging downwards
+func ropePoints(a, b Vec2.Vec2, length float64, segments int) []Vec2.Vec2 {
+	span := Vec2.Distance(a, b)
+	sag := 0.0
+	if length > span && span > 0 {
+		// Arc length of a shallow parabola is about span + 8·sag²/(3·span)
+		sag = math.Sqrt(3 * span * (length - span) / 8)
+	}
+
+	const samples = 256
+	curve := make([]Vec2.Vec2, samples+1)
+	for i := range curve {
+		t := float64(i) / samples
+		curve[i] = a.Add(b.Add(a.Mult(-1)).Mult(t)).Add(Vec2.Vec2{X: 0, Y: -4 * sag * t * (1 - t)})
+	}
+	total := 0.0
+	for i := 1; i < len(curve); i++ {
+		total += Vec2.Distance(curve[i-1], curve[i])
+	}
+
+	points := []Vec2.Vec2{a}
+	step := total / float64(segments)
+	walked, target := 0.0, step
+	for i := 1; i < len(curve) && len(points) < segments; i++ {
+		l := Vec2.Distance(curve[i-1], curve[i])
+		for walked+l >= target && len(points) < segments {
+			t := (target - walked) / l
+			points = append(points, curve[i-1].Add(curve[i].Add(curve[i-1].Mult(-1)).Mult(t)))
+			target += step
+		}
+		walked += l
+	}
+	return append(points, b)
+}
+
+// createRevoluteJoint pins bodies a and b together at a world point
+func createRevoluteJoint(ecs *ecs.ECS, a, b *donburi.Entry, pivot Vec2.Vec2, breakForce float64) *donburi.Entry {
+	entity := ecs.World.Create(components.Joint, components.RevoluteJoint)
+	entry := ecs.World.Entry(entity)
+	components.Joint.SetValue(entry, components.JointData{BodyA: a, BodyB: b, BreakForce: breakForce})
+	components.RevoluteJoint.SetValue(entry, components.RevoluteJointData{
+		LocalAnchorA: components.LocalAnchor(a, pivot),
+		LocalAnchorB: components.LocalAnchor(b, pivot),
+	})
+	return entry
+}
+
+// createDistanceJoint joins world points pA on a and pB on b with a rope joint
+func createDistanceJoint(ecs *ecs.ECS, a, b *donburi.Entry, pA, pB Vec2.Vec2, length, breakForce float64) *donburi.Entry {
+	entity := ecs.World.Create(components.Joint, components.DistanceJoint)
+	entry := ecs.World.Entry(entity)
+	components.Joint.SetValue(entry, components.JointData{BodyA: a, BodyB: b, BreakForce: breakForce})
+	components.DistanceJoint.SetValue(entry, components.DistanceJointData{
+		LocalAnchorA: components.LocalAnchor(a, pA),
+		LocalAnchorB: components.LocalAnchor(b, pB),
+		Length:       length,
+		Rope:         true,
+	})
+	return entry
+}
+
+// CreateRopeDemo builds a pendulum, a sagging bridge with crates on it, a crane carrying a
+// load along a track, and a weak rope that snaps when a ball lands on its crate
+func CreateRopeDemo(ecs *ecs.ECS) {
+	CreateChain(ecs, []Vec2.Vec2{{X: -1000, Y: 800}, {X: -1000, Y: -500}, {X: 1000, Y: -500}, {X: 1000, Y: 800}})
+	wood, _ := components.MaterialPreset("wood")
+	steel, _ := components.MaterialPreset("steel")
+	box := func(pos Vec2.Vec2, half float64, mat components.MaterialData) *donburi.Entry {
+		return CreateCompoundBody(ecs, pos, []components.Shape{
+			{Kind: components.ShapeBox, HalfExtents: Vec2.Vec2{X: half, Y: half}},
+		}, mat)
+	}
+
+	// Pendulum released from the horizontal
+	ball := CreateCompoundBody(ecs, Vec2.Vec2{X: -500, Y: 500}, []components.Shape{
+		{Kind: components.ShapeCircle, Radius: 30},
+	}, steel)
+	CreateRope(ecs, RopeAnchor{Point: Vec2.Vec2{X: -800, Y: 500}}, RopeAnchor{Body: ball, Point: Vec2.Vec2{X: -530, Y: 500}}, RopeSettings{
+		Segments: 15, Mass: 1, Thickness: 4,
+	})
+
+	// Bridge of pinned planks
+	CreateRope(ecs, RopeAnchor{Point: Vec2.Vec2{X: -350, Y: 0}}, RopeAnchor{Point: Vec2.Vec2{X: 250, Y: 0}}, RopeSettings{
+		Segments: 12, Length: 630, Mass: 12, Thickness: 14, Link: RopeRevolute,
+		Color: color.RGBA{R: 140, G: 100, B: 60, A: 255},
+	})
+	box(Vec2.Vec2{X: -150, Y: 150}, 25, wood)
+	box(Vec2.Vec2{X: 50, Y: 250}, 30, wood)
+
+	// Crane trolley shuttling along a track with a chain down to its load
+	trolley := CreateMovingPlatform(ecs, Vec2.Vec2{X: 400, Y: 650}, Vec2.Vec2{X: 850, Y: 650}, Vec2.Vec2{X: 40, Y: 15}, 120)
+	load := box(Vec2.Vec2{X: 400, Y: 350}, 35, steel)
+	CreateRope(ecs, RopeAnchor{Body: trolley, Point: Vec2.Vec2{X: 400, Y: 635}}, RopeAnchor{Body: load, Point: Vec2.Vec2{X: 400, Y: 385}}, RopeSettings{
+		Segments: 10, Mass: 3, Thickness: 8, Link: RopeRevolute,
+		Color: color.RGBA{R: 150, G: 150, B: 160, A: 255},
+	})
+
+	// Holds its crate above the bridge, but tears under the jolt of the falling ball
+	crate := box(Vec2.Vec2{X: -200, Y: 450}, 25, wood)
+	CreateRope(ecs, RopeAnchor{Point: Vec2.Vec2{X: -200, Y: 700}}, RopeAnchor{Body: crate, Point: Vec2.Vec2{X: -200, Y: 475}}, RopeSettings{
+		Segments: 8, Mass: 0.5, Thickness: 4, TearForce: 6000,
+		Color: color.RGBA{R: 220, G: 80, B: 80, A: 255},
+	})
+	CreateCompoundBody(ecs, Vec2.Vec2{X: -195, Y: 650}, []components.Shape{
+		{Kind: components.ShapeCircle, Radius: 25},
+	}, steel)
+}
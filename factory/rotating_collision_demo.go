@@ -1,7 +1,6 @@
 package factory
 
 import (
-	"physengine/assets"
 	"physengine/components"
 	Vec2 "physengine/helpers/vec2"
 
@@ -9,6 +8,50 @@ import (
 	"github.com/yohamta/donburi/ecs"
 )
 
+var RotatingSquarePrefab = Prefab{
+	Name:    "rotating_square",
+	Shape:   PrefabShape{Type: ShapeBox, Min: Vec2.Vec2{X: -60, Y: -60}, Max: Vec2.Vec2{X: 60, Y: 200}},
+	Mass:    8,
+	Inertia: 8 * 8000, // Simplified inertia calculation
+	MaterialData: &components.MaterialData{
+		Restitution:     0.7,
+		StaticFriction:  0.3,
+		DynamicFriction: 0.2,
+	},
+	Sprite: "player.png",
+}
+
+var RotatingCirclePrefab = Prefab{
+	Name:  "rotating_circle",
+	Shape: PrefabShape{Type: ShapeCircle, Radius: 70},
+	Mass:  6,
+	MaterialData: &components.MaterialData{
+		Restitution:     0.8,
+		StaticFriction:  0.4,
+		DynamicFriction: 0.3,
+	},
+	Sprite: "enemy.png",
+}
+
+var StationaryObjectPrefab = Prefab{
+	Name:    "stationary_object",
+	Shape:   PrefabShape{Type: ShapeBox, Min: Vec2.Vec2{X: -80, Y: -80}, Max: Vec2.Vec2{X: 80, Y: 80}},
+	Mass:    20, // Heavy object
+	Inertia: 20 * 12000,
+	MaterialData: &components.MaterialData{
+		Restitution:     0.5,
+		StaticFriction:  0.6,
+		DynamicFriction: 0.4,
+	},
+	Sprite: "player.png",
+}
+
+func init() {
+	for _, prefab := range []Prefab{TestSquarePrefab, TestCirclePrefab, RotatingObjectPrefab, RotatingSquarePrefab, RotatingCirclePrefab, StationaryObjectPrefab} {
+		RegisterPrefab(prefab)
+	}
+}
+
 // CreateRotatingCollisionDemo creates objects to demonstrate rotation-aware collisions
 func CreateRotatingCollisionDemo(ecs *ecs.ECS) {
 	// Create a rotating square that will collide with other objects
@@ -23,92 +66,15 @@ func CreateRotatingCollisionDemo(ecs *ecs.ECS) {
 
 // CreateRotatingSquare creates a square with rotation and collision
 func CreateRotatingSquare(ecs *ecs.ECS, pos Vec2.Vec2, vel Vec2.Vec2, angularVel float64) *donburi.Entry {
-	entity := ecs.World.Create(components.MaterialComponent, components.Transform, components.AABB_Component, components.Drawable, components.MassComponent, components.Velocity, components.AngularVelocity, components.Torque)
-	entry := ecs.World.Entry(entity)
-	components.SetPos(entry, pos)
-	components.Velocity.Get(entry).Velocity = vel
-	components.SetAngularVelocity(entry, angularVel)
-
-	img, _ := assets.GetImage("D:/Coding/physengine/assets/assets/player.png")
-	components.Drawable.Get(entry).Sprite = img
-	components.Transform.Get(entry).Scale = Vec2.Vec2{X: 1, Y: 1}
-
-	mc := components.MassComponent.Get(entry)
-	mc.Mass = 8
-	mc.InverseMass = 1 / mc.Mass
-	// Calculate inertia for a square
-	mc.Inertia = mc.Mass * 8000 // Simplified inertia calculation
-	mc.InverseInertia = 1 / mc.Inertia
-
-	box := components.AABB_Component.Get(entry)
-	box.Min = Vec2.Vec2{-60, -60}
-	box.Max = Vec2.Vec2{60, 200}
-
-	mat := components.MaterialComponent.Get(entry)
-	mat.Restitution = 0.7
-	mat.StaticFriction = 0.3
-	mat.DynamicFriction = 0.2
-
-	return entry
+	return mustSpawn(ecs, RotatingSquarePrefab, pos, &PrefabOverrides{Velocity: &vel, AngularVelocity: &angularVel})
 }
 
 // CreateRotatingCircle creates a circle with rotation and collision
 func CreateRotatingCircle(ecs *ecs.ECS, pos Vec2.Vec2, vel Vec2.Vec2, angularVel float64) *donburi.Entry {
-	entity := ecs.World.Create(components.MaterialComponent, components.Transform, components.CircleCollider, components.Drawable, components.MassComponent, components.Velocity, components.AngularVelocity, components.Torque)
-	entry := ecs.World.Entry(entity)
-	components.SetPos(entry, pos)
-	components.Velocity.Get(entry).Velocity = vel
-	components.SetAngularVelocity(entry, angularVel)
-
-	img, _ := assets.GetImage("D:/Coding/physengine/assets/assets/enemy.png")
-	components.Drawable.Get(entry).Sprite = img
-	components.Transform.Get(entry).Scale = Vec2.Vec2{X: 1, Y: 1}
-
-	mc := components.MassComponent.Get(entry)
-	mc.Mass = 6
-	mc.InverseMass = 1 / mc.Mass
-
-	// Calculate inertia for a circle
-	crcl := components.CircleCollider.Get(entry)
-	crcl.Radius = 70
-	mc.Inertia = mc.Mass * crcl.Radius * crcl.Radius * 0.5 // I = 0.5 * m * r^2 for a circle
-	mc.InverseInertia = 1 / mc.Inertia
-
-	mat := components.MaterialComponent.Get(entry)
-	mat.Restitution = 0.8
-	mat.StaticFriction = 0.4
-	mat.DynamicFriction = 0.3
-
-	return entry
+	return mustSpawn(ecs, RotatingCirclePrefab, pos, &PrefabOverrides{Velocity: &vel, AngularVelocity: &angularVel})
 }
 
 // CreateStationaryObject creates a stationary object for collision testing
 func CreateStationaryObject(ecs *ecs.ECS, pos Vec2.Vec2, vel Vec2.Vec2) *donburi.Entry {
-	entity := ecs.World.Create(components.MaterialComponent, components.Transform, components.AABB_Component, components.Drawable, components.MassComponent, components.Velocity, components.AngularVelocity, components.Torque)
-	entry := ecs.World.Entry(entity)
-	components.SetPos(entry, pos)
-	components.Velocity.Get(entry).Velocity = vel
-	components.SetAngularVelocity(entry, 0.0) // No rotation
-
-	img, _ := assets.GetImage("D:/Coding/physengine/assets/assets/player.png")
-	components.Drawable.Get(entry).Sprite = img
-	components.Transform.Get(entry).Scale = Vec2.Vec2{X: 1, Y: 1}
-
-	mc := components.MassComponent.Get(entry)
-	mc.Mass = 20 // Heavy object
-	mc.InverseMass = 1 / mc.Mass
-	// Calculate inertia for a square
-	mc.Inertia = mc.Mass * 12000
-	mc.InverseInertia = 1 / mc.Inertia
-
-	box := components.AABB_Component.Get(entry)
-	box.Min = Vec2.Vec2{-80, -80}
-	box.Max = Vec2.Vec2{80, 80}
-
-	mat := components.MaterialComponent.Get(entry)
-	mat.Restitution = 0.5
-	mat.StaticFriction = 0.6
-	mat.DynamicFriction = 0.4
-
-	return entry
+	return mustSpawn(ecs, StationaryObjectPrefab, pos, &PrefabOverrides{Velocity: &vel})
 }
@@ -1,7 +1,6 @@
 package factory
 
 import (
-	"physengine/assets"
 	"physengine/components"
 	Vec2 "physengine/helpers/vec2"
 
@@ -9,31 +8,26 @@ import (
 	"github.com/yohamta/donburi/ecs"
 )
 
-// CreateRotatingObject creates an object with applied torque for demonstration
-func CreateRotatingObject(ecs *ecs.ECS, pos Vec2.Vec2, vel Vec2.Vec2, torque float64) *donburi.Entry {
-	entity := ecs.World.Create(components.MaterialComponent, components.Transform, components.CircleCollider, components.Drawable, components.MassComponent, components.Velocity, components.AngularVelocity, components.Torque)
-	entry := ecs.World.Entry(entity)
-	components.SetPos(entry, pos)
-	components.Velocity.Get(entry).Velocity = vel
-	components.SetAngularVelocity(entry, 0.0) // Start with no rotation
-	components.SetTorque(entry, torque) // Apply constant torque
-	
-	img, _ := assets.GetImage("D:/Coding/physengine/assets/assets/enemy.png")
-	components.Drawable.Get(entry).Sprite = img
-	components.Transform.Get(entry).Scale = Vec2.Vec2{X: 1, Y: 1}
-	
-	mc := components.MassComponent.Get(entry)
-	mc.Mass = 15
-	mc.InverseMass = 1 / mc.Mass
-	
-	// Calculate inertia for a circle
-	crcl := components.CircleCollider.Get(entry)
-	crcl.Radius = 80
-	mc.Inertia = mc.Mass * crcl.Radius * crcl.Radius * 0.5 // I = 0.5 * m * r^2 for a circle
-	mc.InverseInertia = 1 / mc.Inertia
-	
-	mat := components.MaterialComponent.Get(entry)
-	mat.Restitution = 0.7
-	
+var RotatingObjectPrefab = Prefab{
+	Name:         "rotating_object",
+	Shape:        PrefabShape{Type: ShapeCircle, Radius: 80},
+	Mass:         15,
+	MaterialData: &components.MaterialData{Restitution: 0.7},
+	Sprite:       "enemy.png",
+}
+
+// CreateMotorizedObject creates an object whose motor spins it up to speed rad/s, applying
+// at most maxTorque
+func CreateMotorizedObject(ecs *ecs.ECS, pos Vec2.Vec2, vel Vec2.Vec2, speed, maxTorque float64) *donburi.Entry {
+	entry := mustSpawn(ecs, RotatingObjectPrefab, pos, &PrefabOverrides{Velocity: &vel})
+	AttachMotor(entry, components.MotorData{Enabled: true, Speed: speed, MaxTorque: maxTorque})
 	return entry
-} 
\ No newline at end of file
+}
+
+// AttachMotor adds a motor to an existing body so it drives the body's rotation
+func AttachMotor(entry *donburi.Entry, motor components.MotorData) {
+	if !entry.HasComponent(components.Motor) {
+		entry.AddComponent(components.Motor)
+	}
+	components.Motor.SetValue(entry, motor)
+}
@@ -0,0 +1,119 @@
+package factory
+
+import (
+	"image/color"
+	"math"
+	"physengine/components"
+	Vec2 "physengine/helpers/vec2"
+
+	"github.com/yohamta/donburi"
+	"github.com/yohamta/donburi/ecs"
+)
+
+// createSoftBody creates a soft body entity from a filled-in network
+func createSoftBody(ecs *ecs.ECS, sb components.SoftBodyData) *donburi.Entry {
+	entity := ecs.World.Create(components.SoftBody, components.Transform)
+	entry := ecs.World.Entry(entity)
+	sb.RestArea = sb.HullArea()
+	components.SoftBody.SetValue(entry, sb)
+	tr := components.Transform.Get(entry)
+	tr.Pos = sb.Center()
+	tr.Scale = Vec2.Vec2{X: 1, Y: 1}
+	return entry
+}
+
+// CreateSoftGrid creates a jelly block of cols x rows points centred on pos, braced with
+// shear springs so it keeps its shape. mass is spread evenly over the points.
+func CreateSoftGrid(ecs *ecs.ECS, pos Vec2.Vec2, cols, rows int, spacing, mass, stiffness float64) *donburi.Entry {
+	sb := components.SoftBodyData{
+		PointRadius: spacing / 4,
+		Friction:    0.6,
+		Fill:        color.RGBA{R: 71, G: 157, B: 94, A: 200},
+		Outline:     color.RGBA{R: 40, G: 120, B: 60, A: 255},
+	}
+	origin := pos.Add(Vec2.Vec2{X: -float64(cols-1) * spacing / 2, Y: -float64(rows-1) * spacing / 2})
+	pointMass := mass / float64(cols*rows)
+	index := func(col, row int) int { return row*cols + col }
+	for row := 0; row < rows; row++ {
+		for col := 0; col < cols; col++ {
+			sb.AddPoint(origin.Add(Vec2.Vec2{X: float64(col) * spacing, Y: float64(row) * spacing}), pointMass)
+		}
+	}
+
+	damping := 0.5 * math.Sqrt(stiffness*pointMass)
+	for row := 0; row < rows; row++ {
+		for col := 0; col < cols; col++ {
+			if col+1 < cols {
+				sb.AddSpring(index(col, row), index(col+1, row), stiffness, damping)
+			}
+			if row+1 < rows {
+				sb.AddSpring(index(col, row), index(col, row+1), stiffness, damping)
+			}
+			if col+1 < cols && row+1 < rows {
+				sb.AddSpring(index(col, row), index(col+1, row+1), stiffness, damping)
+				sb.AddSpring(index(col+1, row), index(col, row+1), stiffness, damping)
+			}
+		}
+	}
+
+	// Walk the border counter-clockwise from the bottom-left corner
+	for col := 0; col < cols; col++ {
+		sb.Hull = append(sb.Hull, index(col, 0))
+	}
+	for row := 1; row < rows; row++ {
+		sb.Hull = append(sb.Hull, index(cols-1, row))
+	}
+	for col := cols - 2; col >= 0; col-- {
+		sb.Hull = append(sb.Hull, index(col, rows-1))
+	}
+	for row := rows - 2; row > 0; row-- {
+		sb.Hull = append(sb.Hull, index(0, row))
+	}
+	return createSoftBody(ecs, sb)
+}
+
+// CreateSoftBlob creates a pressurised ring of points around center, like a water balloon.
+// Higher pressure makes a firmer blob.
+func CreateSoftBlob(ecs *ecs.ECS, center Vec2.Vec2, radius float64, segments int, mass, pressure float64) *donburi.Entry {
+	sb := components.SoftBodyData{
+		Pressure: pressure,
+		Friction: 0.4,
+		Fill:     color.RGBA{R: 56, G: 99, B: 180, A: 180},
+		Outline:  color.RGBA{R: 30, G: 70, B: 200, A: 255},
+	}
+	pointMass := mass / float64(segments)
+	for i := 0; i < segments; i++ {
+		angle := 2 * math.Pi * float64(i) / float64(segments)
+		sb.AddPoint(center.Add(Vec2.Vec2{X: math.Cos(angle) * radius, Y: math.Sin(angle) * radius}), pointMass)
+		sb.Hull = append(sb.Hull, i)
+	}
+	sb.PointRadius = math.Pi * radius / float64(segments)
+
+	// Edge springs hold the skin together; weaker springs across two edges resist creasing
+	stiffness := 40 * pressure
+	damping := 0.5 * math.Sqrt(stiffness*pointMass)
+	for i := 0; i < segments; i++ {
+		sb.AddSpring(i, (i+1)%segments, stiffness, damping)
+		sb.AddSpring(i, (i+2)%segments, stiffness/4, damping)
+	}
+	return createSoftBody(ecs, sb)
+}
+
+// CreateSoftBodyDemo builds a walled floor with a ramp, then drops a jelly block and a
+// blob onto it next to a few rigid bodies
+func CreateSoftBodyDemo(ecs *ecs.ECS) {
+	CreateChain(ecs, []Vec2.Vec2{{X: -800, Y: 600}, {X: -800, Y: -400}, {X: 800, Y: -400}, {X: 800, Y: 600}})
+	CreateRamp(ecs, Vec2.Vec2{X: -750, Y: 100}, Vec2.Vec2{X: -250, Y: -150}, false)
+
+	CreateSoftGrid(ecs, Vec2.Vec2{X: -550, Y: 300}, 6, 5, 25, 6, 2000)
+	CreateSoftBlob(ecs, Vec2.Vec2{X: 150, Y: 200}, 70, 24, 4, 60)
+
+	wood, _ := components.MaterialPreset("wood")
+	steel, _ := components.MaterialPreset("steel")
+	CreateCompoundBody(ecs, Vec2.Vec2{X: 150, Y: 500}, []components.Shape{
+		{Kind: components.ShapeBox, HalfExtents: Vec2.Vec2{X: 40, Y: 40}},
+	}, wood)
+	CreateCompoundBody(ecs, Vec2.Vec2{X: 500, Y: 0}, []components.Shape{
+		{Kind: components.ShapeCircle, Radius: 40},
+	}, steel)
+}
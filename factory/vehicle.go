@@ -0,0 +1,94 @@
+package factory
+
+import (
+	"math"
+	"physengine/components"
+	Vec2 "physengine/helpers/vec2"
+
+	"github.com/yohamta/donburi"
+	"github.com/yohamta/donburi/ecs"
+)
+
+// CreateWheelJoint connects a wheel to a chassis at the wheel's current position. axis is
+// the suspension axis in world space; anchors and axis are stored in the bodies' frames.
+func CreateWheelJoint(ecs *ecs.ECS, chassis, wheel *donburi.Entry, axis Vec2.Vec2, settings components.WheelJointData) *donburi.Entry {
+	entity := ecs.World.Create(components.Joint, components.WheelJoint)
+	entry := ecs.World.Entry(entity)
+	components.Joint.SetValue(entry, components.JointData{BodyA: chassis, BodyB: wheel})
+
+	trA := components.Transform.Get(chassis)
+	trB := components.Transform.Get(wheel)
+	settings.LocalAnchorA = components.RotatePoint(trB.Pos.Add(trA.Pos.Mult(-1)), -trA.Rot)
+	settings.LocalAnchorB = Vec2.Vec2{}
+	settings.LocalAxis = components.RotatePoint(axis.Normalized(), -trA.Rot)
+	components.WheelJoint.SetValue(entry, settings)
+	return entry
+}
+
+// CreateVehicle builds a car facing right with its chassis centred near pos: a wooden body
+// with a cabin, and two sprung wheels driven from input. It returns the chassis, which
+// holds the Vehicle component.
+func CreateVehicle(ecs *ecs.ECS, pos Vec2.Vec2, input components.VehicleInput) *donburi.Entry {
+	wood, _ := components.MaterialPreset("wood")
+	tyre, _ := components.MaterialPreset("tyre")
+	chassis := CreateCompoundBody(ecs, pos, []components.Shape{
+		{Kind: components.ShapeBox, HalfExtents: Vec2.Vec2{X: 100, Y: 20}},
+		{Kind: components.ShapeBox, Offset: Vec2.Vec2{X: -15, Y: 40}, HalfExtents: Vec2.Vec2{X: 45, Y: 20}},
+	}, wood)
+
+	suspension := components.WheelJointDefaults
+	suspension.Frequency = 5
+	up := Vec2.Vec2{X: 0, Y: 1}
+	var wheels []components.VehicleWheel
+	for _, x := range []float64{-70, 70} {
+		wheel := CreateCompoundBody(ecs, pos.Add(Vec2.Vec2{X: x, Y: -45}), []components.Shape{
+			{Kind: components.ShapeCircle, Radius: 28},
+		}, tyre)
+		joint := CreateWheelJoint(ecs, chassis, wheel, up, suspension)
+		wheels = append(wheels, components.VehicleWheel{Joint: joint, Driven: true, Brakes: true})
+	}
+
+	chassis.AddComponent(components.Vehicle)
+	components.Vehicle.SetValue(chassis, components.VehicleData{
+		Input:    input,
+		Wheels:   wheels,
+		MaxSpeed: 25,
+		// Full torque from standstill, fading towards top speed
+		TorqueCurve: []components.TorquePoint{
+			{Speed: 0, Torque: 60000},
+			{Speed: 15, Torque: 50000},
+			{Speed: 25, Torque: 20000},
+		},
+		BrakeTorque:       30000,
+		RollingResistance: 500,
+	})
+	return chassis
+}
+
+// CreateTerrain creates static rolling ground starting at start and running length units
+// to the right. The height is a sum of two sine waves sampled every step units.
+func CreateTerrain(ecs *ecs.ECS, start Vec2.Vec2, length, step, amplitude float64) *donburi.Entry {
+	var points []Vec2.Vec2
+	for x := 0.0; x <= length; x += step {
+		y := amplitude * (math.Sin(x/350) + 0.5*math.Sin(x/130+1))
+		points = append(points, start.Add(Vec2.Vec2{X: x, Y: y}))
+	}
+	return CreateChain(ecs, points)
+}
+
+// CreateVehicleDemo builds hilly terrain walled in at both ends, with a few crates to push
+// and a car driven by input. It returns the car's chassis.
+func CreateVehicleDemo(ecs *ecs.ECS, input components.VehicleInput) *donburi.Entry {
+	wood, _ := components.MaterialPreset("wood")
+	CreateTerrain(ecs, Vec2.Vec2{X: -1500, Y: -300}, 6000, 50, 60)
+	CreateRamp(ecs, Vec2.Vec2{X: -1500, Y: 400}, Vec2.Vec2{X: -1500, Y: -400}, false)
+	CreateRamp(ecs, Vec2.Vec2{X: 4500, Y: -400}, Vec2.Vec2{X: 4500, Y: 400}, false)
+
+	for i, x := range []float64{600, 1400, 1460, 2600} {
+		CreateCompoundBody(ecs, Vec2.Vec2{X: x, Y: 100 + float64(i)*10}, []components.Shape{
+			{Kind: components.ShapeBox, HalfExtents: Vec2.Vec2{X: 25, Y: 25}},
+		}, wood)
+	}
+
+	return CreateVehicle(ecs, Vec2.Vec2{X: -1200, Y: 0}, input)
+}
@@ -1,7 +1,12 @@
 package main
 
 import (
+	"flag"
+	"fmt"
+	"os"
 	"physengine/scenes"
+	"slices"
+	"strings"
 
 	"github.com/hajimehoshi/ebiten/v2"
 )
@@ -21,13 +26,21 @@ func (g *Game) Update() error {
 }
 
 func (g *Game) Layout(width, height int) (int, int) {
+	g.scene.Layout(width, height)
 	return width, height
 }
 
 func main() {
+	scene := flag.String("scene", scenes.SceneSandbox, fmt.Sprintf("scene to run (%s)", strings.Join(scenes.SceneNames, ", ")))
+	flag.Parse()
+	if !slices.Contains(scenes.SceneNames, *scene) {
+		fmt.Fprintf(os.Stderr, "unknown scene %q\n", *scene)
+		os.Exit(2)
+	}
+
 	ebiten.SetWindowSize(1000, 1000)
 	ebiten.SetWindowTitle("ECS game")
-	if err := ebiten.RunGame(&Game{scenes.MyScene{}}); err != nil {
+	if err := ebiten.RunGame(&Game{scenes.MyScene{Name: *scene}}); err != nil {
 		panic(err)
 	}
 }
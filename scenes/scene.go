@@ -1,19 +1,42 @@
 package scenes
 
 import (
+	"fmt"
+	"physengine/components"
 	"physengine/factory"
 	Vec2 "physengine/helpers/vec2"
 	"physengine/systems"
 	"sync"
+	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/yohamta/donburi"
 	"github.com/yohamta/donburi/ecs"
 )
 
+// Scene names accepted by MyScene.Name
+const (
+	SceneSandbox   = "sandbox"
+	SceneVehicle   = "vehicle"
+	SceneSoftBody  = "softbody"
+	SceneRope      = "rope"
+	SceneParticles = "particles"
+	SceneFluid     = "fluid"
+	SceneBuoyancy  = "buoyancy"
+	SceneExplosion = "explosion"
+	SceneFracture  = "fracture"
+	SceneOrbit     = "orbit"
+)
+
+// SceneNames lists the scenes that can be selected
+var SceneNames = []string{SceneSandbox, SceneVehicle, SceneSoftBody, SceneRope, SceneParticles, SceneFluid, SceneBuoyancy, SceneExplosion, SceneFracture, SceneOrbit}
+
 type MyScene struct {
-	ecs  *ecs.ECS
-	once sync.Once
+	Name         string // One of SceneNames, the sandbox if empty
+	ecs          *ecs.ECS
+	once         sync.Once
+	screenWidth  int
+	screenHeight int
 }
 
 func (ms *MyScene) Update() {
@@ -25,22 +48,163 @@ func (ms *MyScene) Draw(screen *ebiten.Image) {
 	ms.ecs.Draw(screen)
 }
 
+// Layout keeps camera viewports in sync with the window size
+func (ms *MyScene) Layout(width, height int) {
+	ms.screenWidth = width
+	ms.screenHeight = height
+	if ms.ecs != nil {
+		components.ResizeCameras(ms.ecs.World, width, height)
+	}
+}
+
 func (ms *MyScene) configure() {
 	ms.ecs = ecs.NewECS(donburi.NewWorld())
 	ms.ecs.AddSystem(systems.UpdateCamera)
-	ms.ecs.AddSystem(systems.UpdateImprovedCollisions)
-	ms.ecs.AddSystem(systems.UpdateVelocity)
-	ms.ecs.AddSystem(systems.UpdateTorque)
-	ms.ecs.AddSystem(systems.UpdateAngularVelocity)
+	ms.ecs.AddSystem(systems.FixedStep(
+		systems.UpdatePlatformPaths,
+		systems.UpdateExplosives,
+		systems.UpdateBuoyancy,
+		systems.MeasureResolver(systems.UpdateImprovedCollisions),
+		systems.UpdateContactSparks,
+		systems.UpdateFracture,
+		systems.UpdateVehicles,
+		systems.UpdateJoints,
+		systems.UpdateRopes,
+		systems.UpdateSoftBodies,
+		systems.UpdateFluids,
+		systems.UpdateParticles,
+		systems.UpdateCharacterControllers,
+		systems.UpdateMotors,
+		systems.UpdateIntegration,
+		systems.UpdateTelemetry,
+	))
+	ms.ecs.AddSystem(systems.UpdateSpriteAnimation)
+	ms.ecs.AddSystem(systems.UpdateDebugDraw)
+	ms.ecs.AddSystem(systems.UpdateAssetHotReload)
 	ms.ecs.AddRenderer(0, systems.DrawCamera)
+	ms.ecs.AddRenderer(0, systems.DrawDebugHUD)
 	factory.CreateCamera(ms.ecs)
+	if ms.screenWidth > 0 && ms.screenHeight > 0 {
+		components.ResizeCameras(ms.ecs.World, ms.screenWidth, ms.screenHeight)
+	}
+	factory.CreatePhysicsWorld(ms.ecs)
 	factory.CreateCollisionResolver(ms.ecs)
+	factory.CreateDebugDraw(ms.ecs)
+
+	if err := factory.LoadPrefabs("prefabs.json"); err != nil {
+		fmt.Println(err)
+	}
+
+	switch ms.Name {
+	case SceneVehicle:
+		ms.configureVehicle()
+	case SceneSoftBody:
+		ms.configureSoftBody()
+	case SceneRope:
+		ms.configureRope()
+	case SceneParticles:
+		ms.configureParticles()
+	case SceneFluid:
+		ms.configureFluid()
+	case SceneBuoyancy:
+		ms.configureBuoyancy()
+	case SceneExplosion:
+		ms.configureExplosion()
+	case SceneFracture:
+		ms.configureFracture()
+	case SceneOrbit:
+		ms.configureOrbit()
+	default:
+		ms.configureSandbox()
+	}
+}
 
+// configureSandbox fills the gravity-free sandbox with one of every kind of body
+func (ms *MyScene) configureSandbox() {
 	// Create demo objects for rotation-aware collision testing
 	factory.CreateRotatingCollisionDemo(ms.ecs)
 
 	// Original demo objects
 	factory.CreateTestSquare(ms.ecs, Vec2.Vec2{X: 0, Y: 300}, Vec2.Vec2{X: 0, Y: -150})
 	factory.CreateTestCircle(ms.ecs, Vec2.Vec2{X: 100, Y: -100}, Vec2.Vec2{X: 0, Y: 100})
-	factory.CreateRotatingObject(ms.ecs, Vec2.Vec2{X: -200, Y: 0}, Vec2.Vec2{X: 50, Y: 0}, 5000.0) // Object with applied torque
+	factory.CreateMotorizedObject(ms.ecs, Vec2.Vec2{X: -200, Y: 0}, Vec2.Vec2{X: 50, Y: 0}, 2.0, 50000.0) // Spun up to 2 rad/s by a motor
+
+	// Compound body with its centre of mass near the heavy head
+	factory.CreateHammer(ms.ecs, Vec2.Vec2{X: -500, Y: 300}, Vec2.Vec2{X: 40, Y: -20})
+
+	// Capsules against a ramp and a one-sided platform
+	factory.CreateCapsuleSegmentDemo(ms.ecs)
+
+	// Static level geometry: chains and a tilemap
+	factory.CreateLevelGeometryDemo(ms.ecs)
+
+	// Concave outlines split into convex pieces
+	factory.CreatePolygonDemo(ms.ecs)
+
+	// Keyboard-driven character on the ground chain, with a platform to ride
+	factory.CreateCharacter(ms.ecs, Vec2.Vec2{X: -200, Y: -700}, systems.KeyboardCharacterInput{})
+	factory.CreateMovingPlatform(ms.ecs, Vec2.Vec2{X: 100, Y: -650}, Vec2.Vec2{X: 500, Y: -500}, Vec2.Vec2{X: 80, Y: 10}, 100)
+}
+
+// configureVehicle drops a keyboard-driven car onto hilly terrain under gravity, with the
+// camera following the car
+func (ms *MyScene) configureVehicle() {
+	components.GetPhysicsWorld(ms.ecs.World).Gravity = Vec2.Vec2{X: 0, Y: -600}
+	car := factory.CreateVehicleDemo(ms.ecs, systems.KeyboardVehicleInput{})
+	if camera, ok := components.Camera.First(ms.ecs.World); ok {
+		components.Camera.Get(camera).Follow = car
+	}
+}
+
+// configureSoftBody drops a jelly block and a pressurised blob into a box under gravity
+func (ms *MyScene) configureSoftBody() {
+	components.GetPhysicsWorld(ms.ecs.World).Gravity = Vec2.Vec2{X: 0, Y: -600}
+	factory.CreateSoftBodyDemo(ms.ecs)
+}
+
+// configureRope hangs ropes, a bridge and a crane chain under gravity
+func (ms *MyScene) configureRope() {
+	components.GetPhysicsWorld(ms.ecs.World).Gravity = Vec2.Vec2{X: 0, Y: -600}
+	factory.CreateRopeDemo(ms.ecs)
+}
+
+// configureParticles shows a fountain, a smoke trail and impact sparks under gravity
+func (ms *MyScene) configureParticles() {
+	components.GetPhysicsWorld(ms.ecs.World).Gravity = Vec2.Vec2{X: 0, Y: -600}
+	factory.CreateParticleDemo(ms.ecs)
+}
+
+// configureFluid fills a tank with water and a bin with sand, with bodies floating and sinking in them
+func (ms *MyScene) configureFluid() {
+	components.GetPhysicsWorld(ms.ecs.World).Gravity = Vec2.Vec2{X: 0, Y: -600}
+	factory.CreateFluidDemo(ms.ecs)
+}
+
+// configureBuoyancy floats bodies in a flowing pool and a pond of brine under gravity
+func (ms *MyScene) configureBuoyancy() {
+	components.GetPhysicsWorld(ms.ecs.World).Gravity = Vec2.Vec2{X: 0, Y: -600}
+	factory.CreateBuoyancyDemo(ms.ecs)
+}
+
+// configureExplosion blows up towers of crates under gravity, one sheltered behind a wall
+func (ms *MyScene) configureExplosion() {
+	components.GetPhysicsWorld(ms.ecs.World).Gravity = Vec2.Vec2{X: 0, Y: -600}
+	factory.CreateExplosionDemo(ms.ecs)
+}
+
+// configureFracture smashes breakable crates and shatters falling bodies under gravity
+func (ms *MyScene) configureFracture() {
+	components.GetPhysicsWorld(ms.ecs.World).Gravity = Vec2.Vec2{X: 0, Y: -600}
+	factory.CreateFractureDemo(ms.ecs)
+}
+
+// configureOrbit runs a small solar system without damping under mutual gravitation, in
+// fixed steps with velocity Verlet so the orbits keep their energy
+func (ms *MyScene) configureOrbit() {
+	world := components.GetPhysicsWorld(ms.ecs.World)
+	world.DefaultLinearDamping, world.DefaultAngularDamping, world.AirDensity = 0, 0, 0
+	world.GravitationalConstant = 2000
+	world.FixedStep, world.MaxSubsteps = time.Second/120, 8
+	world.Integrator = components.IntegratorVelocityVerlet
+	factory.CreateOrbitDemo(ms.ecs)
 }
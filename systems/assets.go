@@ -0,0 +1,12 @@
+package systems
+
+import (
+	"physengine/assets"
+
+	"github.com/yohamta/donburi/ecs"
+)
+
+// UpdateAssetHotReload reloads changed asset files when hot-reload is enabled
+func UpdateAssetHotReload(e *ecs.ECS) {
+	assets.Default.Poll()
+}
@@ -0,0 +1,187 @@
+package systems
+
+import (
+	"math"
+	Vec2 "physengine/helpers/vec2"
+)
+
+const (
+	// nbodyDirectLimit is the source count up to which attractors are summed directly,
+	// below which building a quadtree costs more than it saves
+	nbodyDirectLimit = 32
+	// quadTreeMaxDepth stops subdividing around sources at nearly the same point
+	quadTreeMaxDepth = 24
+)
+
+// gravitySource is one attractor seen by the gravity field
+type gravitySource struct {
+	pos       Vec2.Vec2
+	mass      float64
+	softening float64
+	body      int // Index of the integrated body it belongs to, -1 if it is fixed
+}
+
+// quadNode is a square cell of a Barnes–Hut quadtree holding the total mass and centre of
+// mass of the sources inside it
+type quadNode struct {
+	center    Vec2.Vec2
+	half      float64 // Half the side of the square
+	mass      float64
+	com       Vec2.Vec2
+	softening float64 // Largest softening inside, so far cells never pull harder than near ones
+	children  [4]int  // Node indices, 0 where there is no child
+	sources   []int   // Source indices, only in leaves
+}
+
+// gravityField finds the pull of a set of attractors, directly for few of them and with
+// a Barnes–Hut quadtree for many
+type gravityField struct {
+	sources []gravitySource
+	g       float64
+	theta   float64
+	nodes   []quadNode
+}
+
+func newGravityField(sources []gravitySource, g, theta float64) *gravityField {
+	f := &gravityField{sources: sources, g: g, theta: theta}
+	if len(sources) > nbodyDirectLimit && theta > 0 {
+		f.build()
+	}
+	return f
+}
+
+// build inserts every source into a quadtree covering all of them
+func (f *gravityField) build() {
+	min := Vec2.Vec2{X: math.Inf(1), Y: math.Inf(1)}
+	max := Vec2.Vec2{X: math.Inf(-1), Y: math.Inf(-1)}
+	for _, s := range f.sources {
+		min = Vec2.Vec2{X: math.Min(min.X, s.pos.X), Y: math.Min(min.Y, s.pos.Y)}
+		max = Vec2.Vec2{X: math.Max(max.X, s.pos.X), Y: math.Max(max.Y, s.pos.Y)}
+	}
+	half := math.Max(max.X-min.X, max.Y-min.Y)/2 + 1
+	f.nodes = append(f.nodes[:0], quadNode{center: min.Add(max).Mult(0.5), half: half})
+	for i := range f.sources {
+		f.insert(0, i, 0)
+	}
+	f.summarize(0)
+}
+
+func (f *gravityField) insert(node, source, depth int) {
+	n := &f.nodes[node]
+	isLeaf := n.children == [4]int{}
+	if isLeaf && (len(n.sources) == 0 || depth >= quadTreeMaxDepth) {
+		n.sources = append(n.sources, source)
+		return
+	}
+	if isLeaf {
+		// Split the leaf and push its source down with the new one
+		moved := n.sources
+		n.sources = nil
+		for _, s := range moved {
+			f.insertChild(node, s, depth)
+		}
+	}
+	f.insertChild(node, source, depth)
+}
+
+// insertChild inserts a source into the quadrant of node that contains it
+func (f *gravityField) insertChild(node, source, depth int) {
+	n := f.nodes[node]
+	p := f.sources[source].pos
+	quadrant := 0
+	offset := Vec2.Vec2{X: -n.half / 2, Y: -n.half / 2}
+	if p.X >= n.center.X {
+		quadrant |= 1
+		offset.X = n.half / 2
+	}
+	if p.Y >= n.center.Y {
+		quadrant |= 2
+		offset.Y = n.half / 2
+	}
+	child := n.children[quadrant]
+	if child == 0 {
+		child = len(f.nodes)
+		f.nodes = append(f.nodes, quadNode{center: n.center.Add(offset), half: n.half / 2})
+		f.nodes[node].children[quadrant] = child
+	}
+	f.insert(child, source, depth+1)
+}
+
+// summarize fills in the mass, centre of mass and softening of a node and its children
+func (f *gravityField) summarize(node int) {
+	n := &f.nodes[node]
+	weighted := Vec2.Vec2{}
+	for _, i := range n.sources {
+		s := f.sources[i]
+		n.mass += s.mass
+		weighted.AddUpdate(s.pos.Mult(s.mass))
+		n.softening = math.Max(n.softening, s.softening)
+	}
+	for _, child := range n.children {
+		if child == 0 {
+			continue
+		}
+		f.summarize(child)
+		c := f.nodes[child]
+		n.mass += c.mass
+		weighted.AddUpdate(c.com.Mult(c.mass))
+		n.softening = math.Max(n.softening, c.softening)
+	}
+	if n.mass > 0 {
+		n.com = weighted.Mult(1 / n.mass)
+	}
+}
+
+// at returns the acceleration and potential per unit mass at p of a body with its own
+// softening length, leaving out the sources of the integrated body self. The softening
+// lengths of both ends add in quadrature so every pair pulls equally both ways.
+func (f *gravityField) at(p Vec2.Vec2, softening float64, self int) (Vec2.Vec2, float64) {
+	var acc Vec2.Vec2
+	potential := 0.0
+	add := func(pos Vec2.Vec2, mass, sourceSoftening float64) {
+		r := pos.Add(p.Mult(-1))
+		d2 := r.SquareMagnitude() + sourceSoftening*sourceSoftening + softening*softening
+		if d2 <= 0 {
+			return
+		}
+		d := math.Sqrt(d2)
+		acc.AddUpdate(r.Mult(f.g * mass / (d2 * d)))
+		potential -= f.g * mass / d
+	}
+	addSource := func(i int) {
+		if s := f.sources[i]; s.body < 0 || s.body != self {
+			add(s.pos, s.mass, s.softening)
+		}
+	}
+
+	if len(f.nodes) == 0 {
+		for i := range f.sources {
+			addSource(i)
+		}
+		return acc, potential
+	}
+
+	stack := []int{0}
+	for len(stack) > 0 {
+		n := f.nodes[stack[len(stack)-1]]
+		stack = stack[:len(stack)-1]
+		if n.mass == 0 {
+			continue
+		}
+		inside := math.Abs(p.X-n.center.X) <= n.half && math.Abs(p.Y-n.center.Y) <= n.half
+		if !inside && 2*n.half < f.theta*Vec2.Distance(p, n.com) {
+			// Far enough away to pull as one mass
+			add(n.com, n.mass, n.softening)
+			continue
+		}
+		for _, i := range n.sources {
+			addSource(i)
+		}
+		for _, child := range n.children {
+			if child != 0 {
+				stack = append(stack, child)
+			}
+		}
+	}
+	return acc, potential
+}
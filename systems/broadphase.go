@@ -0,0 +1,73 @@
+package systems
+
+import (
+	"physengine/components"
+	"sort"
+
+	"github.com/yohamta/donburi"
+)
+
+// broadphaseProxies returns one proxy per collision shape. Static shapes come from the
+// resolver's cache, which is rebuilt only when the number of static bodies changes or
+// InvalidateStaticBroadphase was called.
+func broadphaseProxies(resolver *components.CollisionResolverData) []components.BroadphaseProxy {
+	var dynamic []components.BroadphaseProxy
+	var static []*donburi.Entry
+	for _, entry := range resolver.Physobs {
+		if components.IsStatic(entry) {
+			static = append(static, entry)
+			continue
+		}
+		dynamic = appendProxies(dynamic, entry, false)
+	}
+
+	if !resolver.StaticCacheValid(len(static)) {
+		proxies := []components.BroadphaseProxy{}
+		for _, entry := range static {
+			proxies = appendProxies(proxies, entry, true)
+		}
+		sortProxies(proxies)
+		resolver.SetStaticProxies(proxies, len(static))
+	}
+
+	proxies := make([]components.BroadphaseProxy, 0, len(dynamic)+len(resolver.StaticProxies))
+	proxies = append(proxies, dynamic...)
+	proxies = append(proxies, resolver.StaticProxies...)
+	sortProxies(proxies)
+	return proxies
+}
+
+func appendProxies(proxies []components.BroadphaseProxy, entry *donburi.Entry, static bool) []components.BroadphaseProxy {
+	for _, shape := range components.WorldShapes(entry) {
+		min, max := shape.Bounds()
+		proxies = append(proxies, components.BroadphaseProxy{Entry: entry, Shape: shape, Min: min, Max: max, Static: static})
+	}
+	return proxies
+}
+
+func sortProxies(proxies []components.BroadphaseProxy) {
+	sort.SliceStable(proxies, func(i, j int) bool {
+		return proxies[i].Min.X < proxies[j].Min.X
+	})
+}
+
+// sweepPairs calls fn for every pair of proxies whose bounds overlap, using sort and sweep
+// along X. Shapes of the same body and pairs of static shapes are skipped.
+func sweepPairs(proxies []components.BroadphaseProxy, fn func(a, b *components.BroadphaseProxy)) {
+	for i := range proxies {
+		a := &proxies[i]
+		for j := i + 1; j < len(proxies); j++ {
+			b := &proxies[j]
+			if b.Min.X > a.Max.X {
+				break
+			}
+			if a.Entry == b.Entry || (a.Static && b.Static) {
+				continue
+			}
+			if a.Max.Y < b.Min.Y || b.Max.Y < a.Min.Y {
+				continue
+			}
+			fn(a, b)
+		}
+	}
+}
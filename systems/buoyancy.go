@@ -0,0 +1,105 @@
+package systems
+
+import (
+	"math"
+	"physengine/components"
+	Vec2 "physengine/helpers/vec2"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/yohamta/donburi"
+	"github.com/yohamta/donburi/ecs"
+	"github.com/yohamta/donburi/filter"
+)
+
+// UpdateBuoyancy pushes dynamic bodies out of the buoyancy volumes they sit in. Each shape's
+// submerged area gives an upward force at the centroid of that area, so tilted bodies right
+// themselves, and drag pulls the submerged part towards the flow velocity. Like gravity it
+// runs before the collision step.
+func UpdateBuoyancy(e *ecs.ECS) {
+	dt := stepSeconds(e)
+	if dt <= 0 {
+		return
+	}
+	world := components.GetPhysicsWorld(e.World)
+	var gravity Vec2.Vec2
+	if world != nil {
+		gravity = world.Gravity
+	}
+
+	var volumes []*components.BuoyancyVolumeData
+	for entry := range components.BuoyancyVolume.Iter(e.World) {
+		volumes = append(volumes, components.BuoyancyVolume.Get(entry))
+	}
+	if len(volumes) == 0 {
+		return
+	}
+
+	query := donburi.NewQuery(filter.And(
+		components.ColliderFilter(),
+		filter.Contains(components.Transform, components.Velocity, components.MassComponent),
+		filter.Not(filter.Contains(components.StaticBody)),
+	))
+	for entry := range query.Iter(e.World) {
+		mass := components.MassComponent.Get(entry)
+		if mass.InverseMass <= 0 {
+			continue
+		}
+		body := newRigidBody(entry)
+		for _, shape := range components.WorldShapes(entry) {
+			shapeMin, shapeMax := shape.Bounds()
+			for _, volume := range volumes {
+				min, max := volume.Bounds()
+				if shapeMax.X < min.X || shapeMin.X > max.X || shapeMax.Y < min.Y || shapeMin.Y > max.Y {
+					continue
+				}
+				area, centroid := volume.Submerged(shape)
+				if area <= 0 {
+					continue
+				}
+				applyBuoyancy(&body, volume, area, centroid, gravity, dt)
+			}
+		}
+	}
+}
+
+// applyBuoyancy applies the buoyant force and drag of one submerged area to a body
+func applyBuoyancy(body *rigidBody, volume *components.BuoyancyVolumeData, area float64, centroid, gravity Vec2.Vec2, dt float64) {
+	displaced := volume.Density * area
+	r := centroid.Add(body.pos.Mult(-1))
+
+	// Archimedes: the weight of the displaced fluid, pointing against gravity
+	buoyancy := gravity.Mult(-displaced * dt)
+	body.applyImpulse(buoyancy, Vec2.CrossProductVecVec(r, buoyancy))
+
+	// Drag grows with the displaced mass and is capped so light bodies cannot overshoot
+	relVel := body.velocityAt(r).Add(volume.Flow.Mult(-1))
+	linear := math.Min(volume.LinearDrag*displaced*body.invMass*dt, 1)
+	drag := relVel.Mult(-linear / body.invMass)
+	body.applyImpulse(drag, Vec2.CrossProductVecVec(r, drag))
+
+	if body.invI > 0 {
+		angular := math.Min(volume.AngularDrag*displaced*body.invMass*dt, 1)
+		*body.angVel *= 1 - angular
+	}
+}
+
+// drawBuoyancyVolumes fills every buoyancy volume seen by the camera
+func drawBuoyancyVolumes(e *ecs.ECS, camera *donburi.Entry, screen_camera *ebiten.Image) {
+	camera_tr := components.Transform.Get(camera)
+	camera_comp := components.Camera.Get(camera)
+	for entry := range components.BuoyancyVolume.Iter(e.World) {
+		if !camera_comp.SeesEntity(entry) {
+			continue
+		}
+		volume := components.BuoyancyVolume.Get(entry)
+		polygon := volume.Polygon()
+		if len(polygon) < 3 || volume.Color.A == 0 {
+			continue
+		}
+
+		toScreen := func(p Vec2.Vec2) Vec2.Vec2 {
+			return camera_comp.WorldToScreen(camera_tr.Pos, p)
+		}
+		fillWorldPolygon(screen_camera, toScreen, polygon, volume.Color)
+	}
+}
@@ -0,0 +1,294 @@
+package systems
+
+import (
+	"math"
+	"physengine/components"
+	Vec2 "physengine/helpers/vec2"
+
+	"github.com/yohamta/donburi"
+	"github.com/yohamta/donburi/ecs"
+)
+
+// maxSlideIterations limits how many surfaces one move can slide along
+const maxSlideIterations = 4
+
+// characterObstacle is a shape near a character that it can collide with this step
+type characterObstacle struct {
+	entry    *donburi.Entry
+	shape    components.WorldShape
+	min, max Vec2.Vec2
+}
+
+// UpdateCharacterControllers applies input, gravity and jumps to every character, then moves
+// it with move-and-slide against all other colliders and probes for ground
+func UpdateCharacterControllers(e *ecs.ECS) {
+	dt := stepSeconds(e)
+	if dt <= 0 {
+		return
+	}
+	for entry := range components.CharacterController.Iter(e.World) {
+		updateCharacter(e, entry, dt)
+	}
+}
+
+func updateCharacter(e *ecs.ECS, entry *donburi.Entry, dt float64) {
+	cc := components.CharacterController.Get(entry)
+	tr := components.Transform.Get(entry)
+	shapes := components.WorldShapes(entry)
+	if len(shapes) == 0 {
+		return
+	}
+
+	move, jumpPressed, jumpHeld := 0.0, false, false
+	if cc.Input != nil {
+		move = math.Max(-1, math.Min(1, cc.Input.MoveAxis()))
+		jumpPressed = cc.Input.JumpPressed()
+		jumpHeld = cc.Input.JumpHeld()
+	}
+
+	// Coyote time and jump buffering forgive pressing jump slightly too late or too early
+	if jumpPressed {
+		cc.JumpBufferTimer = cc.JumpBufferTime
+	} else {
+		cc.JumpBufferTimer -= dt
+	}
+	if cc.Grounded {
+		cc.CoyoteTimer = cc.CoyoteTime
+	} else {
+		cc.CoyoteTimer -= dt
+	}
+
+	accel := cc.AirAcceleration
+	if cc.Grounded {
+		accel = cc.Acceleration
+	}
+	cc.Velocity.X = approach(cc.Velocity.X, move*cc.MoveSpeed, accel*dt)
+
+	carry := groundVelocity(cc, tr.Pos)
+	jumped := false
+	if cc.JumpBufferTimer > 0 && cc.CoyoteTimer > 0 {
+		cc.Velocity.Y = cc.JumpSpeed
+		cc.JumpBufferTimer = 0
+		cc.CoyoteTimer = 0
+		cc.Grounded = false
+		jumped = true
+	}
+	if cc.Grounded {
+		cc.Velocity.Y = 0
+	} else {
+		gravity := cc.Gravity
+		if cc.Velocity.Y > 0 && !jumpHeld {
+			gravity *= 2 // Releasing jump early gives a shorter hop
+		}
+		cc.Velocity.Y = math.Max(cc.Velocity.Y-gravity*dt, -cc.MaxFallSpeed)
+	}
+
+	// On the ground, walk along its tangent so slopes neither launch nor drop the character
+	displacement := cc.Velocity.Mult(dt)
+	if cc.Grounded {
+		tangent := Vec2.Vec2{X: cc.GroundNormal.Y, Y: -cc.GroundNormal.X}
+		displacement = tangent.Mult(cc.Velocity.X * dt)
+	}
+	displacement.AddUpdate(carry.Mult(dt))
+
+	wasGrounded := cc.Grounded
+	reach := displacement.Magnitude() + cc.StepHeight + 4*cc.SkinWidth + 1
+	obstacles := characterObstacles(e, entry, shapes, reach)
+
+	offset := moveAndSlide(cc, shapes, displacement, obstacles)
+	offset = depenetrate(shapes, offset, obstacles)
+
+	// Probe for ground, snapping down steps and slopes while walking
+	cc.Grounded = false
+	cc.Ground = nil
+	if !jumped && cc.Velocity.Y <= 0 {
+		probe := 2 * cc.SkinWidth
+		if wasGrounded {
+			probe += cc.StepHeight
+		}
+		down := Vec2.Vec2{X: 0, Y: -probe}
+		if hit, ground, ok := castCharacter(shapes, offset, down, obstacles); ok && cc.Supports(hit, feetY(shapes, offset)) {
+			offset.AddUpdate(Vec2.Vec2{X: 0, Y: -math.Max(hit.T*probe-cc.SkinWidth, 0)})
+			cc.Grounded = true
+			cc.Ground = ground
+			cc.GroundNormal = hit.Normal
+			cc.Velocity.Y = 0
+		}
+	}
+
+	components.SetPos(entry, tr.Pos.Add(offset))
+	if entry.HasComponent(components.Velocity) {
+		components.Velocity.Get(entry).Velocity = cc.Velocity.Add(carry)
+	}
+}
+
+// moveAndSlide moves the character shapes along delta, sliding along whatever they hit,
+// and returns the offset actually travelled
+func moveAndSlide(cc *components.CharacterControllerData, shapes []components.WorldShape, delta Vec2.Vec2, obstacles []characterObstacle) Vec2.Vec2 {
+	var offset Vec2.Vec2
+	remaining := delta
+	for i := 0; i < maxSlideIterations && remaining.SquareMagnitude() > 1e-8; i++ {
+		hit, _, ok := castCharacter(shapes, offset, remaining, obstacles)
+		if !ok {
+			offset.AddUpdate(remaining)
+			break
+		}
+		normal := hit.Normal
+
+		// Stop short of the surface by the skin width so the next cast does not start touching
+		length := remaining.Magnitude()
+		travel := math.Max(hit.T*length-cc.SkinWidth, 0)
+		offset.AddUpdate(remaining.Mult(travel / length))
+		remaining = remaining.Mult(1 - travel/length)
+
+		supported := cc.Supports(hit, feetY(shapes, offset))
+		if cc.Grounded && !supported {
+			if stepped, ok := stepUp(cc, shapes, offset, remaining, obstacles); ok {
+				offset = stepped
+				break
+			}
+			// Steep slopes act as walls, so sliding cannot push the character up them
+			if normal.Y > 0 && math.Abs(normal.X) > 1e-6 {
+				normal = Vec2.Vec2{X: math.Copysign(1, normal.X), Y: 0}
+			}
+		}
+
+		if into := Vec2.DotProduct(remaining, normal); into < 0 {
+			remaining = remaining.Add(normal.Mult(-into))
+		}
+		// Walking onto new ground keeps the walking speed, it follows the ground tangent next step
+		if into := Vec2.DotProduct(cc.Velocity, normal); into < 0 && !(cc.Grounded && supported) {
+			cc.Velocity = cc.Velocity.Add(normal.Mult(-into))
+		}
+	}
+	return offset
+}
+
+// stepUp tries to climb a ledge: lift by the step height, move across and settle back down
+// onto walkable ground
+func stepUp(cc *components.CharacterControllerData, shapes []components.WorldShape, offset, remaining Vec2.Vec2, obstacles []characterObstacle) (Vec2.Vec2, bool) {
+	if cc.StepHeight <= 0 || math.Abs(remaining.X) < 1e-6 {
+		return offset, false
+	}
+	feet := feetY(shapes, offset)
+	up := Vec2.Vec2{X: 0, Y: cc.StepHeight}
+	if hit, _, ok := castCharacter(shapes, offset, up, obstacles); ok {
+		up = up.Mult(math.Max(hit.T-cc.SkinWidth/cc.StepHeight, 0))
+	}
+	raised := offset.Add(up)
+
+	across := Vec2.Vec2{X: remaining.X, Y: 0}
+	if hit, _, ok := castCharacter(shapes, raised, across, obstacles); ok {
+		if hit.T*math.Abs(across.X) <= cc.SkinWidth {
+			return offset, false
+		}
+		across = across.Mult(hit.T - cc.SkinWidth/math.Abs(across.X))
+	}
+	raised.AddUpdate(across)
+
+	drop := up.Y + 2*cc.SkinWidth
+	hit, _, ok := castCharacter(shapes, raised, Vec2.Vec2{X: 0, Y: -drop}, obstacles)
+	if !ok || !cc.Supports(hit, feet) {
+		return offset, false
+	}
+	return raised.Add(Vec2.Vec2{X: 0, Y: -math.Max(hit.T*drop-cc.SkinWidth, 0)}), true
+}
+
+// feetY returns the lowest point of the character shapes placed at offset
+func feetY(shapes []components.WorldShape, offset Vec2.Vec2) float64 {
+	feet := math.Inf(1)
+	for _, shape := range shapes {
+		min, _ := shape.Bounds()
+		feet = math.Min(feet, min.Y+offset.Y)
+	}
+	return feet
+}
+
+// depenetrate pushes the character out of anything that moved into it, such as a platform
+func depenetrate(shapes []components.WorldShape, offset Vec2.Vec2, obstacles []characterObstacle) Vec2.Vec2 {
+	for _, shape := range shapes {
+		for _, obstacle := range obstacles {
+			contact, ok := components.CollideShapes(obstacle.shape, shape.Translated(offset))
+			if ok && contact.Penetration > components.CastTolerance {
+				offset.AddUpdate(contact.Normal.Mult(contact.Penetration))
+			}
+		}
+	}
+	return offset
+}
+
+// castCharacter sweeps all character shapes, placed at offset, along delta and returns the
+// earliest hit and the body it belongs to
+func castCharacter(shapes []components.WorldShape, offset, delta Vec2.Vec2, obstacles []characterObstacle) (components.ShapeCastHit, *donburi.Entry, bool) {
+	var best components.ShapeCastHit
+	var bestEntry *donburi.Entry
+	found := false
+	for _, shape := range shapes {
+		moved := shape.Translated(offset)
+		min, max := moved.Bounds()
+		min = Vec2.Vec2{X: min.X + math.Min(delta.X, 0), Y: min.Y + math.Min(delta.Y, 0)}
+		max = Vec2.Vec2{X: max.X + math.Max(delta.X, 0), Y: max.Y + math.Max(delta.Y, 0)}
+		for _, obstacle := range obstacles {
+			if max.X < obstacle.min.X || obstacle.max.X < min.X || max.Y < obstacle.min.Y || obstacle.max.Y < min.Y {
+				continue
+			}
+			if hit, ok := components.CastShape(moved, delta, obstacle.shape); ok && (!found || hit.T < best.T) {
+				best = hit
+				bestEntry = obstacle.entry
+				found = true
+			}
+		}
+	}
+	return best, bestEntry, found
+}
+
+// characterObstacles collects the shapes of other bodies within reach of the character
+func characterObstacles(e *ecs.ECS, self *donburi.Entry, shapes []components.WorldShape, reach float64) []characterObstacle {
+	min, max := shapes[0].Bounds()
+	for _, s := range shapes[1:] {
+		smin, smax := s.Bounds()
+		min = Vec2.Vec2{X: math.Min(min.X, smin.X), Y: math.Min(min.Y, smin.Y)}
+		max = Vec2.Vec2{X: math.Max(max.X, smax.X), Y: math.Max(max.Y, smax.Y)}
+	}
+	min = Vec2.Vec2{X: min.X - reach, Y: min.Y - reach}
+	max = Vec2.Vec2{X: max.X + reach, Y: max.Y + reach}
+
+	var obstacles []characterObstacle
+	query := donburi.NewQuery(components.ColliderFilter())
+	for entry := range query.Iter(e.World) {
+		if entry.Entity() == self.Entity() {
+			continue
+		}
+		for _, shape := range components.WorldShapes(entry) {
+			smin, smax := shape.Bounds()
+			if max.X < smin.X || smax.X < min.X || max.Y < smin.Y || smax.Y < min.Y {
+				continue
+			}
+			obstacles = append(obstacles, characterObstacle{entry: entry, shape: shape, min: smin, max: smax})
+		}
+	}
+	return obstacles
+}
+
+// groundVelocity returns the velocity of the ground under the character, so it rides along
+// with moving and rotating platforms
+func groundVelocity(cc *components.CharacterControllerData, pos Vec2.Vec2) Vec2.Vec2 {
+	if !cc.Grounded || cc.Ground == nil || !cc.Ground.Valid() || !cc.Ground.HasComponent(components.Velocity) {
+		return Vec2.Vec2{}
+	}
+	vel := components.Velocity.Get(cc.Ground).Velocity
+	if cc.Ground.HasComponent(components.AngularVelocity) {
+		w := components.AngularVelocity.Get(cc.Ground).AngularVelocity
+		r := pos.Add(components.Transform.Get(cc.Ground).Pos.Mult(-1))
+		vel = vel.Add(Vec2.Vec2{X: -w * r.Y, Y: w * r.X})
+	}
+	return vel
+}
+
+// approach moves value towards target by at most step
+func approach(value, target, step float64) float64 {
+	if value < target {
+		return math.Min(value+step, target)
+	}
+	return math.Max(value-step, target)
+}
@@ -0,0 +1,28 @@
+package systems
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// KeyboardCharacterInput drives a character with A/D or the arrow keys, and Space, W or Up to jump
+type KeyboardCharacterInput struct{}
+
+func (KeyboardCharacterInput) MoveAxis() float64 {
+	axis := 0.0
+	if ebiten.IsKeyPressed(ebiten.KeyA) || ebiten.IsKeyPressed(ebiten.KeyArrowLeft) {
+		axis--
+	}
+	if ebiten.IsKeyPressed(ebiten.KeyD) || ebiten.IsKeyPressed(ebiten.KeyArrowRight) {
+		axis++
+	}
+	return axis
+}
+
+func (KeyboardCharacterInput) JumpPressed() bool {
+	return inpututil.IsKeyJustPressed(ebiten.KeySpace) || inpututil.IsKeyJustPressed(ebiten.KeyW) || inpututil.IsKeyJustPressed(ebiten.KeyArrowUp)
+}
+
+func (KeyboardCharacterInput) JumpHeld() bool {
+	return ebiten.IsKeyPressed(ebiten.KeySpace) || ebiten.IsKeyPressed(ebiten.KeyW) || ebiten.IsKeyPressed(ebiten.KeyArrowUp)
+}
@@ -0,0 +1,72 @@
+package systems
+
+import (
+	"math"
+	"physengine/components"
+	Vec2 "physengine/helpers/vec2"
+
+	"github.com/yohamta/donburi"
+)
+
+// dampLinearVelocity applies exponential damping and quadratic air drag to a body's velocity
+func dampLinearVelocity(entry *donburi.Entry, world *components.PhysicsWorldData, dt float64) {
+	if !entry.HasComponent(components.Velocity) {
+		return
+	}
+	vel := components.Velocity.Get(entry)
+
+	rate := 0.0
+	if entry.HasComponent(components.Damping) {
+		rate = components.Damping.Get(entry).Linear
+	} else if world != nil {
+		rate = world.DefaultLinearDamping
+	}
+	if rate > 0 {
+		vel.Velocity.MultUpdate(math.Exp(-rate * dt))
+	}
+
+	if world == nil || world.AirDensity <= 0 || !entry.HasComponent(components.AirDrag) || !entry.HasComponent(components.MassComponent) {
+		return
+	}
+	mass := components.MassComponent.Get(entry)
+	speed := vel.Velocity.Magnitude()
+	if mass.InverseMass <= 0 || speed < 0.001 {
+		return
+	}
+	area := crossSection(entry, vel.Velocity.Mult(1/speed))
+	k := 0.5 * world.AirDensity * components.AirDrag.Get(entry).Coefficient * area * mass.InverseMass
+	// Exact solution of dv/dt = -k·v² over the step, so drag can never reverse the velocity
+	vel.Velocity.MultUpdate(1 / (1 + k*speed*dt))
+}
+
+// dampAngularVelocity applies exponential damping to a body's angular velocity
+func dampAngularVelocity(entry *donburi.Entry, world *components.PhysicsWorldData, dt float64) {
+	if !entry.HasComponent(components.AngularVelocity) {
+		return
+	}
+	angVel := components.AngularVelocity.Get(entry)
+	rate := 0.0
+	if entry.HasComponent(components.Damping) {
+		rate = components.Damping.Get(entry).Angular
+	} else if world != nil {
+		rate = world.DefaultAngularDamping
+	}
+	if rate > 0 {
+		angVel.AngularVelocity *= math.Exp(-rate * dt)
+	}
+}
+
+// crossSection returns the width of a body's colliders seen along a unit direction
+func crossSection(entry *donburi.Entry, dir Vec2.Vec2) float64 {
+	perp := Vec2.Vec2{X: -dir.Y, Y: dir.X}
+	min, max := math.Inf(1), math.Inf(-1)
+	for _, shape := range components.WorldShapes(entry) {
+		smin, smax := shape.Project(perp)
+		min = math.Min(min, smin)
+		max = math.Max(max, smax)
+	}
+	if max < min {
+		return 0
+	}
+	return max - min
+}
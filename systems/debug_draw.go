@@ -0,0 +1,342 @@
+package systems
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"physengine/components"
+	Vec2 "physengine/helpers/vec2"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/yohamta/donburi"
+	"github.com/yohamta/donburi/ecs"
+)
+
+// Debug colours are premultiplied, as color.RGBA always is
+var (
+	debugShapeColor       = color.White
+	debugContactColor     = color.RGBA{R: 255, G: 60, B: 60, A: 255}
+	debugNormalColor      = color.RGBA{R: 255, G: 200, B: 0, A: 255}
+	debugVelocityColor    = color.RGBA{R: 60, G: 200, B: 255, A: 255}
+	debugBoundsColor      = color.RGBA{R: 50, G: 160, B: 50, A: 160}
+	debugMassColor        = color.RGBA{R: 255, G: 0, B: 255, A: 255}
+	debugJointColor       = color.RGBA{R: 120, G: 255, B: 200, A: 255}
+	debugSleepColor       = color.RGBA{R: 110, G: 110, B: 200, A: 255}
+	debugProxyColor       = color.RGBA{R: 160, G: 94, B: 38, A: 160}
+	debugStaticProxyColor = color.RGBA{R: 100, G: 63, B: 38, A: 160}
+	debugKineticColor     = color.RGBA{R: 60, G: 200, B: 255, A: 255}
+	debugPenColor         = color.RGBA{R: 255, G: 60, B: 60, A: 255}
+)
+
+// UpdateDebugDraw toggles debug overlay features from the keyboard:
+// F1 shapes, F2 contacts, F3 normals, F4 velocities, F5 bounds, F6 centre of mass, F7 stats, F8 joints,
+// F11 broadphase proxies, F12 sleeping bodies.
+// F9 switches to the next integrator, F10 exports the telemetry.
+func UpdateDebugDraw(e *ecs.ECS) {
+	if inpututil.IsKeyJustPressed(ebiten.KeyF9) {
+		cycleIntegrator(e.World)
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyF10) {
+		exportTelemetry(e.World)
+	}
+
+	entry, ok := components.DebugDraw.First(e.World)
+	if !ok {
+		return
+	}
+	dd := components.DebugDraw.Get(entry)
+
+	toggles := []struct {
+		key ebiten.Key
+		val *bool
+	}{
+		{ebiten.KeyF1, &dd.Shapes},
+		{ebiten.KeyF2, &dd.Contacts},
+		{ebiten.KeyF3, &dd.Normals},
+		{ebiten.KeyF4, &dd.Velocities},
+		{ebiten.KeyF5, &dd.Bounds},
+		{ebiten.KeyF6, &dd.CenterOfMass},
+		{ebiten.KeyF7, &dd.Stats},
+		{ebiten.KeyF8, &dd.Joints},
+		{ebiten.KeyF11, &dd.Broadphase},
+		{ebiten.KeyF12, &dd.Sleep},
+	}
+	for _, t := range toggles {
+		if inpututil.IsKeyJustPressed(t.key) {
+			*t.val = !*t.val
+		}
+	}
+}
+
+// cycleIntegrator switches the world to the next integrator and restarts the drift count
+func cycleIntegrator(w donburi.World) {
+	world := components.GetPhysicsWorld(w)
+	if world == nil {
+		return
+	}
+	world.Integrator = world.Integrator.Next()
+	if entry, ok := components.IntegrationStats.First(w); ok {
+		components.IntegrationStats.Get(entry).Reset()
+	}
+}
+
+// drawDebug draws the enabled debug overlay features for one camera
+func drawDebug(e *ecs.ECS, camera *donburi.Entry, screen_camera *ebiten.Image) {
+	dd_entry, ok := components.DebugDraw.First(e.World)
+	if !ok {
+		return
+	}
+	dd := components.DebugDraw.Get(dd_entry)
+	camera_tr := components.Transform.Get(camera)
+	camera_comp := components.Camera.Get(camera)
+	toScreen := func(p Vec2.Vec2) Vec2.Vec2 {
+		return camera_comp.WorldToScreen(camera_tr.Pos, p)
+	}
+	zoom := (camera_comp.Zoom.X + camera_comp.Zoom.Y) / 2
+
+	bodies := donburi.NewQuery(components.ColliderFilter())
+	for entry := range bodies.Iter(e.World) {
+		if !camera_comp.SeesEntity(entry) {
+			continue
+		}
+		tr := components.Transform.Get(entry)
+
+		asleep := dd.Sleep && components.IsAsleep(entry)
+		if dd.Shapes || asleep {
+			var clr color.Color = debugShapeColor
+			if asleep {
+				clr = debugSleepColor
+			}
+			drawDebugShape(screen_camera, entry, toScreen, zoom, clr)
+		}
+		if dd.Bounds {
+			if min, max, ok := components.WorldBounds(entry); ok {
+				strokeWorldPolygon(screen_camera, toScreen, []Vec2.Vec2{
+					min, {X: max.X, Y: min.Y}, max, {X: min.X, Y: max.Y},
+				}, 1, debugBoundsColor)
+			}
+		}
+		if dd.Velocities && entry.HasComponent(components.Velocity) {
+			// Arrow length shows the distance travelled in a quarter second
+			vel := components.Velocity.Get(entry).Velocity
+			strokeWorldLine(screen_camera, toScreen, tr.Pos, tr.Pos.Add(vel.Mult(0.25)), 2, debugVelocityColor)
+		}
+		if dd.CenterOfMass {
+			p := toScreen(tr.Pos)
+			vector.StrokeLine(screen_camera, float32(p.X-5), float32(p.Y), float32(p.X+5), float32(p.Y), 2, debugMassColor, false)
+			vector.StrokeLine(screen_camera, float32(p.X), float32(p.Y-5), float32(p.X), float32(p.Y+5), 2, debugMassColor, false)
+		}
+	}
+
+	if dd.Joints {
+		drawDebugJoints(e, screen_camera, toScreen, zoom)
+	}
+
+	resolver_entry, ok := components.CollisionResolverComponent.First(e.World)
+	if !ok {
+		return
+	}
+	resolver := components.CollisionResolverComponent.Get(resolver_entry)
+	if dd.Broadphase {
+		drawDebugProxies(screen_camera, resolver, toScreen)
+	}
+	if !dd.Contacts && !dd.Normals {
+		return
+	}
+	for _, contact := range resolver.Contacts {
+		if dd.Contacts {
+			p := toScreen(contact.Point)
+			vector.DrawFilledCircle(screen_camera, float32(p.X), float32(p.Y), 4, debugContactColor, false)
+		}
+		if dd.Normals {
+			// Normals are drawn with a fixed screen length
+			end := contact.Point.Add(contact.Normal.Mult(30 / zoom))
+			strokeWorldLine(screen_camera, toScreen, contact.Point, end, 2, debugNormalColor)
+		}
+	}
+}
+
+// drawDebugProxies outlines the bounds the broadphase sorts and sweeps, the cached static
+// proxies in one colour and the proxies of moving bodies in another
+func drawDebugProxies(screen_camera *ebiten.Image, resolver *components.CollisionResolverData, toScreen func(Vec2.Vec2) Vec2.Vec2) {
+	proxies := append([]components.BroadphaseProxy(nil), resolver.StaticProxies...)
+	for _, entry := range resolver.Physobs {
+		if !components.IsStatic(entry) {
+			proxies = appendProxies(proxies, entry, false)
+		}
+	}
+	for _, proxy := range proxies {
+		clr := debugProxyColor
+		if proxy.Static {
+			clr = debugStaticProxyColor
+		}
+		min, max := proxy.Min, proxy.Max
+		strokeWorldPolygon(screen_camera, toScreen, []Vec2.Vec2{
+			min, {X: max.X, Y: min.Y}, max, {X: min.X, Y: max.Y},
+		}, 1, clr)
+	}
+}
+
+// drawDebugJoints draws each joint as a line between its two anchors, with a short stroke
+// along the suspension axis of wheel joints
+func drawDebugJoints(e *ecs.ECS, screen_camera *ebiten.Image, toScreen func(Vec2.Vec2) Vec2.Vec2, zoom float64) {
+	for entry := range components.Joint.Iter(e.World) {
+		pA, pB, ok := components.JointAnchors(entry)
+		if !ok {
+			continue
+		}
+		if entry.HasComponent(components.WheelJoint) {
+			rot := components.Transform.Get(components.Joint.Get(entry).BodyA).Rot
+			axis := components.RotatePoint(components.WheelJoint.Get(entry).LocalAxis.Normalized(), rot).Mult(20 / zoom)
+			strokeWorldLine(screen_camera, toScreen, pA.Add(axis.Mult(-1)), pA.Add(axis), 1, debugJointColor)
+		}
+		strokeWorldLine(screen_camera, toScreen, pA, pB, 2, debugJointColor)
+		p := toScreen(pA)
+		vector.StrokeCircle(screen_camera, float32(p.X), float32(p.Y), 3, 1, debugJointColor, false)
+	}
+}
+
+// drawDebugShape strokes the outlines of all colliders of an entity in the given colour
+func drawDebugShape(screen_camera *ebiten.Image, entry *donburi.Entry, toScreen func(Vec2.Vec2) Vec2.Vec2, zoom float64, clr color.Color) {
+	obj_tr := components.Transform.Get(entry)
+
+	for _, shape := range components.WorldShapes(entry) {
+		switch shape.Kind {
+		case components.ShapeCircle:
+			p := toScreen(shape.Center)
+			// Scale the radius by the camera zoom (use average of X and Y zoom for consistency)
+			vector.StrokeCircle(screen_camera, float32(p.X), float32(p.Y), float32(shape.Radius*zoom), 2, clr, false)
+			// Radius line shows the rotation of the body
+			edge := shape.Center.Add(components.RotatePoint(Vec2.Vec2{X: shape.Radius, Y: 0}, obj_tr.Rot))
+			strokeWorldLine(screen_camera, toScreen, shape.Center, edge, 1, clr)
+		case components.ShapeCapsule, components.ShapeSegment:
+			a, b := shape.Vertices[0], shape.Vertices[1]
+			if shape.Radius > 0 {
+				strokeWorldPolygon(screen_camera, toScreen, capsuleOutline(a, b, shape.Radius), 2, clr)
+			} else {
+				strokeWorldLine(screen_camera, toScreen, a, b, 2, clr)
+			}
+			if shape.OneSided {
+				// Tick on the solid side
+				mid := a.Add(b).Mult(0.5)
+				strokeWorldLine(screen_camera, toScreen, mid, mid.Add(shape.Normal.Mult(10)), 1, clr)
+			}
+		default:
+			strokeWorldPolygon(screen_camera, toScreen, shape.Vertices, 2, clr)
+		}
+	}
+}
+
+// capsuleOutline returns the outline of segment ab inflated by r as a polygon
+func capsuleOutline(a, b Vec2.Vec2, r float64) []Vec2.Vec2 {
+	const capSegments = 8
+	dir := b.Add(a.Mult(-1))
+	angle := 0.0
+	if dir.SquareMagnitude() > 0 {
+		angle = math.Atan2(dir.Y, dir.X)
+	}
+	points := make([]Vec2.Vec2, 0, 2*(capSegments+1))
+	for _, end := range []struct {
+		center Vec2.Vec2
+		start  float64
+	}{{b, angle - math.Pi/2}, {a, angle + math.Pi/2}} {
+		for i := 0; i <= capSegments; i++ {
+			t := end.start + math.Pi*float64(i)/capSegments
+			points = append(points, end.center.Add(Vec2.Vec2{X: r * math.Cos(t), Y: r * math.Sin(t)}))
+		}
+	}
+	return points
+}
+
+// DrawDebugHUD prints physics statistics in the top-left corner of the window
+func DrawDebugHUD(e *ecs.ECS, screen *ebiten.Image) {
+	dd_entry, ok := components.DebugDraw.First(e.World)
+	if !ok || !components.DebugDraw.Get(dd_entry).Stats {
+		return
+	}
+
+	bodies, pairs, contacts, static := 0, 0, 0, 0
+	step_ms := 0.0
+	if resolver_entry, ok := components.CollisionResolverComponent.First(e.World); ok {
+		resolver := components.CollisionResolverComponent.Get(resolver_entry)
+		bodies = len(resolver.Physobs)
+		pairs = resolver.PairCount
+		contacts = len(resolver.Contacts)
+		static = len(resolver.StaticProxies)
+		step_ms = float64(resolver.StepDuration.Microseconds()) / 1000
+	}
+
+	integrator := "none"
+	if world := components.GetPhysicsWorld(e.World); world != nil {
+		integrator = world.Integrator.String()
+	}
+	var stats components.IntegrationStatsData
+	if stats_entry, ok := components.IntegrationStats.First(e.World); ok {
+		stats = *components.IntegrationStats.Get(stats_entry)
+	}
+
+	var samples []components.TelemetrySample
+	var last components.TelemetrySample
+	if telemetry_entry, ok := components.Telemetry.First(e.World); ok {
+		telemetry := components.Telemetry.Get(telemetry_entry)
+		samples = telemetry.Samples()
+		last, _ = telemetry.Last()
+	}
+
+	msg := fmt.Sprintf("TPS %.1f  FPS %.1f\nbodies %d  static shapes %d  pairs %d  contacts %d\nstep %.3f ms\n%s  energy %.4g  drift %.4g\nmomentum (%.4g, %.4g)  angular %.4g\n"+
+		"kinetic %.4g  penetration max %.3g mean %.3g\nresolver dP (%.3g, %.3g)  dL %.3g  dKE %.3g\n"+
+		"F1 shapes F2 contacts F3 normals F4 velocities\nF5 bounds F6 centre of mass F7 stats F8 joints\nF9 integrator F10 export telemetry\nF11 broadphase F12 sleeping",
+		ebiten.ActualTPS(), ebiten.ActualFPS(), bodies, static, pairs, contacts, step_ms, integrator, stats.Energy(), stats.Drift, stats.Momentum.X, stats.Momentum.Y, stats.AngularMomentum,
+		last.Kinetic, last.MaxPenetration, last.MeanPenetration, last.ResolverMomentum.X, last.ResolverMomentum.Y, last.ResolverAngularMomentum, last.ResolverKinetic)
+	ebitenutil.DebugPrint(screen, msg)
+
+	// Kinetic energy and largest penetration over the recorded steps, each scaled to its own peak
+	graph := image.Rect(4, 180, 244, 240)
+	drawTelemetryGraph(screen, graph, samples, func(s components.TelemetrySample) float64 { return s.Kinetic }, debugKineticColor)
+	drawTelemetryGraph(screen, graph, samples, func(s components.TelemetrySample) float64 { return s.MaxPenetration }, debugPenColor)
+}
+
+// drawTelemetryGraph plots one value of the samples as a line across the box, from zero at
+// the bottom to the largest value at the top
+func drawTelemetryGraph(screen *ebiten.Image, box image.Rectangle, samples []components.TelemetrySample, value func(components.TelemetrySample) float64, clr color.Color) {
+	if len(samples) < 2 {
+		return
+	}
+	peak := 0.0
+	for _, s := range samples {
+		peak = math.Max(peak, value(s))
+	}
+	if peak <= 0 {
+		peak = 1
+	}
+	w, h := float64(box.Dx()), float64(box.Dy())
+	point := func(i int) (float32, float32) {
+		x := float64(box.Min.X) + w*float64(i)/float64(len(samples)-1)
+		y := float64(box.Max.Y) - h*value(samples[i])/peak
+		return float32(x), float32(y)
+	}
+	// Long histories are thinned to about one segment per pixel
+	stride := 1 + len(samples)/box.Dx()
+	x0, y0 := point(0)
+	for i := stride; i < len(samples); i += stride {
+		x1, y1 := point(i)
+		vector.StrokeLine(screen, x0, y0, x1, y1, 1, clr, false)
+		x0, y0 = x1, y1
+	}
+}
+
+func strokeWorldLine(dst *ebiten.Image, toScreen func(Vec2.Vec2) Vec2.Vec2, a, b Vec2.Vec2, width float32, clr color.Color) {
+	sa := toScreen(a)
+	sb := toScreen(b)
+	vector.StrokeLine(dst, float32(sa.X), float32(sa.Y), float32(sb.X), float32(sb.Y), width, clr, false)
+}
+
+func strokeWorldPolygon(dst *ebiten.Image, toScreen func(Vec2.Vec2) Vec2.Vec2, points []Vec2.Vec2, width float32, clr color.Color) {
+	for i := range points {
+		strokeWorldLine(dst, toScreen, points[i], points[(i+1)%len(points)], width, clr)
+	}
+}
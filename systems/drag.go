@@ -13,8 +13,13 @@ import (
 )
 
 func UpdateDrag(e *ecs.ECS) {
-	query := donburi.NewQuery(filter.Contains(components.Draggable, components.CircleCollider, components.Transform))
-	cam, _ := components.Camera.First(e.World)
+	query := donburi.NewQuery(filter.And(filter.Contains(components.Draggable), components.ColliderFilter()))
+	// Drag in the world of the camera under the cursor
+	cursor_x, cursor_y := ebiten.CursorPosition()
+	cam := components.CameraAt(e.World, Vec2.Vec2{X: float64(cursor_x), Y: float64(cursor_y)})
+	if cam == nil {
+		return
+	}
 	cam_comp := components.Camera.Get(cam)
 
 	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButton0) {
@@ -0,0 +1,141 @@
+package systems
+
+import (
+	"math"
+	"physengine/components"
+	Vec2 "physengine/helpers/vec2"
+
+	"github.com/yohamta/donburi"
+	"github.com/yohamta/donburi/ecs"
+)
+
+// explosionOcclusionMargin stops occlusion rays just short of their target, so shapes that
+// merely touch the target point do not hide it
+const explosionOcclusionMargin = 1.0
+
+// UpdateExplosives burns down the fuses of explosive entities and detonates the ones that
+// run out
+func UpdateExplosives(e *ecs.ECS) {
+	dt := stepSeconds(e)
+	var detonated []*donburi.Entry
+	for entry := range components.Explosive.Iter(e.World) {
+		explosive := components.Explosive.Get(entry)
+		explosive.Fuse -= dt
+		if explosive.Fuse <= 0 {
+			detonated = append(detonated, entry)
+		}
+	}
+	for _, entry := range detonated {
+		explosive := *components.Explosive.Get(entry)
+		center := components.Transform.Get(entry).Pos
+		// Remove the bomb first so it neither shelters nor pushes itself
+		e.World.Remove(entry.Entity())
+		if explosive.Occluded {
+			ApplyOccludedExplosion(e.World, center, explosive.Radius, explosive.Impulse, explosive.Falloff)
+		} else {
+			ApplyExplosion(e.World, center, explosive.Radius, explosive.Impulse, explosive.Falloff)
+		}
+	}
+}
+
+// ApplyExplosion pushes every dynamic body within radius of center directly away from it.
+// Each body takes the impulse at the point of its surface nearest the centre, so a blast
+// that catches a box off-centre also spins it. It returns the bodies that were pushed.
+func ApplyExplosion(world donburi.World, center Vec2.Vec2, radius, impulse float64, falloff components.ExplosionFalloff) []*donburi.Entry {
+	return applyExplosion(world, center, radius, impulse, falloff, false)
+}
+
+// ApplyOccludedExplosion is ApplyExplosion for a blast that other shapes shelter bodies
+// from. A body is pushed only if a ray from the centre reaches its nearest surface point,
+// or failing that its centre of mass, where the impulse is then applied.
+func ApplyOccludedExplosion(world donburi.World, center Vec2.Vec2, radius, impulse float64, falloff components.ExplosionFalloff) []*donburi.Entry {
+	return applyExplosion(world, center, radius, impulse, falloff, true)
+}
+
+func applyExplosion(world donburi.World, center Vec2.Vec2, radius, impulse float64, falloff components.ExplosionFalloff, occlude bool) []*donburi.Entry {
+	if radius <= 0 {
+		return nil
+	}
+	var proxies []components.BroadphaseProxy
+	for entry := range donburi.NewQuery(components.ColliderFilter()).Iter(world) {
+		proxies = appendProxies(proxies, entry, components.IsStatic(entry))
+	}
+	sortProxies(proxies)
+
+	// The nearest surface point of every body in range
+	type target struct {
+		point Vec2.Vec2
+		dist  float64
+	}
+	targets := map[*donburi.Entry]target{}
+	var order []*donburi.Entry
+	reach := Vec2.Vec2{X: radius, Y: radius}
+	for _, proxy := range nearbyProxies(proxies, center.Add(reach.Mult(-1)), center.Add(reach)) {
+		if proxy.Static {
+			continue
+		}
+		point := proxy.Shape.ClosestPoint(center)
+		dist := Vec2.Distance(point, center)
+		if dist > radius {
+			continue
+		}
+		nearest, seen := targets[proxy.Entry]
+		if !seen {
+			order = append(order, proxy.Entry)
+		}
+		if !seen || dist < nearest.dist {
+			targets[proxy.Entry] = target{point: point, dist: dist}
+		}
+	}
+
+	var pushed []*donburi.Entry
+	for _, entry := range order {
+		body := newRigidBody(entry)
+		if body.invMass <= 0 {
+			continue
+		}
+		nearest := targets[entry]
+		point := nearest.point
+		if occlude && explosionOccluded(center, point, entry, proxies) {
+			if explosionOccluded(center, body.pos, entry, proxies) {
+				continue
+			}
+			point = body.pos
+		}
+
+		dir := point.Add(center.Mult(-1))
+		if dir.SquareMagnitude() < 1e-12 {
+			// The blast went off inside the body
+			dir = body.pos.Add(center.Mult(-1))
+		}
+		if dir.SquareMagnitude() < 1e-12 {
+			dir = Vec2.Vec2{X: 0, Y: 1}
+		}
+		j := dir.Normalized().Mult(impulse * falloff.Scale(nearest.dist, radius))
+		body.applyImpulse(j, Vec2.CrossProductVecVec(point.Add(body.pos.Mult(-1)), j))
+		pushed = append(pushed, entry)
+	}
+	return pushed
+}
+
+// explosionOccluded reports whether any shape other than the target body's lies on the
+// straight line from an explosion to a point of the target
+func explosionOccluded(center, point Vec2.Vec2, target *donburi.Entry, proxies []components.BroadphaseProxy) bool {
+	delta := point.Add(center.Mult(-1))
+	dist := delta.Magnitude()
+	if dist <= explosionOcclusionMargin {
+		return false
+	}
+	to := center.Add(delta.Mult((dist - explosionOcclusionMargin) / dist))
+	min := Vec2.Vec2{X: math.Min(center.X, to.X), Y: math.Min(center.Y, to.Y)}
+	max := Vec2.Vec2{X: math.Max(center.X, to.X), Y: math.Max(center.Y, to.Y)}
+	for _, proxy := range nearbyProxies(proxies, min, max) {
+		if proxy.Entry == target {
+			continue
+		}
+		if _, hit := components.Raycast(center, to, proxy.Shape); hit {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,71 @@
+package systems
+
+import (
+	"image"
+	"image/color"
+	Vec2 "physengine/helpers/vec2"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// fillSourceImage is a white pixel used as the source image of solid-colour triangles
+var fillSourceImage *ebiten.Image
+
+// fillSource returns the shared white pixel, creating it on first use
+func fillSource() *ebiten.Image {
+	if fillSourceImage == nil {
+		img := ebiten.NewImage(3, 3)
+		img.Fill(color.White)
+		fillSourceImage = img.SubImage(image.Rect(1, 1, 2, 2)).(*ebiten.Image)
+	}
+	return fillSourceImage
+}
+
+// fillTriangles draws solid-colour triangles sampled from fillSource. Vertex colours are
+// premultiplied, as color.RGBA always is, so every solid fill is drawn in the matching
+// colour scale mode here.
+func fillTriangles(dst *ebiten.Image, vertices []ebiten.Vertex, indices []uint16, op ebiten.DrawTrianglesOptions) {
+	op.ColorScaleMode = ebiten.ColorScaleModePremultipliedAlpha
+	dst.DrawTriangles(vertices, indices, fillSource(), &op)
+}
+
+// colorVertices samples every vertex from fillSource in one colour
+func colorVertices(vertices []ebiten.Vertex, clr color.RGBA) {
+	r, g, b, a := float32(clr.R)/0xff, float32(clr.G)/0xff, float32(clr.B)/0xff, float32(clr.A)/0xff
+	for i := range vertices {
+		vertices[i].SrcX, vertices[i].SrcY = 1, 1
+		vertices[i].ColorR, vertices[i].ColorG, vertices[i].ColorB, vertices[i].ColorA = r, g, b, a
+	}
+}
+
+// fillWorldPolygon fills a world-space polygon with a solid colour, anti-aliased
+func fillWorldPolygon(dst *ebiten.Image, toScreen func(Vec2.Vec2) Vec2.Vec2, points []Vec2.Vec2, clr color.RGBA) {
+	var path vector.Path
+	for i, p := range points {
+		s := toScreen(p)
+		if i == 0 {
+			path.MoveTo(float32(s.X), float32(s.Y))
+		} else {
+			path.LineTo(float32(s.X), float32(s.Y))
+		}
+	}
+	path.Close()
+
+	vertices, indices := path.AppendVerticesAndIndicesForFilling(nil, nil)
+	colorVertices(vertices, clr)
+	fillTriangles(dst, vertices, indices, ebiten.DrawTrianglesOptions{FillRule: ebiten.FillRuleNonZero, AntiAlias: true})
+}
+
+// appendFilledQuad appends a solid-colour screen-space rectangle, centred on center with
+// half extents hx and hy, to a batch drawn with fillTriangles
+func appendFilledQuad(vertices []ebiten.Vertex, indices []uint16, center Vec2.Vec2, hx, hy float64, clr color.RGBA) ([]ebiten.Vertex, []uint16) {
+	base := uint16(len(vertices))
+	for _, corner := range [4][2]float64{{-1, -1}, {1, -1}, {1, 1}, {-1, 1}} {
+		vertices = append(vertices, ebiten.Vertex{
+			DstX: float32(center.X + corner[0]*hx), DstY: float32(center.Y + corner[1]*hy),
+		})
+	}
+	colorVertices(vertices[base:], clr)
+	return vertices, append(indices, base, base+1, base+2, base, base+2, base+3)
+}
@@ -0,0 +1,68 @@
+package systems
+
+import (
+	"physengine/components"
+
+	"github.com/yohamta/donburi/ecs"
+)
+
+// defaultMaxSubsteps caps the fixed steps per frame when the world does not set MaxSubsteps
+const defaultMaxSubsteps = 4
+
+// FixedStep returns a system that runs the given physics systems in steps of the world's
+// FixedStep, as many as the frame time covers, so the simulation does not depend on the
+// frame rate. Without a FixedStep they run once with the frame time. Physics systems read
+// the length of the current step with stepSeconds.
+func FixedStep(physics ...ecs.System) ecs.System {
+	return func(e *ecs.ECS) {
+		clock_entry, ok := components.PhysicsClock.First(e.World)
+		if !ok {
+			for _, system := range physics {
+				system(e)
+			}
+			return
+		}
+		clock := components.PhysicsClock.Get(clock_entry)
+		frame := e.Time.DeltaTime()
+
+		world := components.GetPhysicsWorld(e.World)
+		if world == nil || world.FixedStep <= 0 {
+			clock.Step, clock.Steps, clock.Accumulator = frame, 1, 0
+			for _, system := range physics {
+				system(e)
+			}
+			return
+		}
+
+		maxSubsteps := world.MaxSubsteps
+		if maxSubsteps <= 0 {
+			maxSubsteps = defaultMaxSubsteps
+		}
+		clock.Step = world.FixedStep
+		clock.Accumulator += frame
+		clock.Steps = 0
+		for clock.Accumulator >= clock.Step && clock.Steps < maxSubsteps {
+			for _, system := range physics {
+				system(e)
+			}
+			clock.Accumulator -= clock.Step
+			clock.Steps++
+		}
+		// Drop what a slow frame could not catch up on rather than falling further behind
+		if clock.Accumulator >= clock.Step {
+			clock.Dropped += clock.Accumulator - clock.Accumulator%clock.Step
+			clock.Accumulator %= clock.Step
+		}
+	}
+}
+
+// stepSeconds returns the length of the physics step being simulated, which is the frame
+// time unless FixedStep runs fixed steps
+func stepSeconds(e *ecs.ECS) float64 {
+	if clock_entry, ok := components.PhysicsClock.First(e.World); ok {
+		if step := components.PhysicsClock.Get(clock_entry).Step; step > 0 {
+			return step.Seconds()
+		}
+	}
+	return e.Time.DeltaTime().Seconds()
+}
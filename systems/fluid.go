@@ -0,0 +1,309 @@
+package systems
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"physengine/components"
+	Vec2 "physengine/helpers/vec2"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/yohamta/donburi"
+	"github.com/yohamta/donburi/ecs"
+)
+
+// fluidSubsteps splits each step so the stiff pressure forces stay stable
+const fluidSubsteps = 2
+
+// UpdateFluids steps every SPH fluid: densities and pressures from neighbouring particles,
+// pressure and viscosity forces, gravity, then contacts with rigid bodies, which feel the
+// contact impulses as buoyancy and drag. It runs after the collision step, whose
+// broadphase proxies it reuses.
+func UpdateFluids(e *ecs.ECS) {
+	dt := stepSeconds(e)
+	if dt <= 0 {
+		return
+	}
+	world := components.GetPhysicsWorld(e.World)
+
+	var proxies []components.BroadphaseProxy
+	if resolver_entry, ok := components.CollisionResolverComponent.First(e.World); ok {
+		proxies = broadphaseProxies(components.CollisionResolverComponent.Get(resolver_entry))
+	}
+
+	h := dt / fluidSubsteps
+	for entry := range components.Fluid.Iter(e.World) {
+		fluid := components.Fluid.Get(entry)
+		if len(fluid.Particles) == 0 || fluid.ParticleMass <= 0 || fluid.SmoothingRadius <= 0 {
+			continue
+		}
+		var gravity Vec2.Vec2
+		if world != nil {
+			gravity = world.Gravity.Mult(components.GetGravityScale(entry))
+		}
+
+		min, max := fluid.Bounds()
+		margin := fluid.ParticleRadius + 50
+		nearby := nearbyProxies(proxies, min.Add(Vec2.Vec2{X: -margin, Y: -margin}), max.Add(Vec2.Vec2{X: margin, Y: margin}))
+
+		grid := newSpatialHash(fluid.SmoothingRadius)
+		accel := make([]Vec2.Vec2, len(fluid.Particles))
+		invMass := 1 / fluid.ParticleMass
+		// Particles may not move further than their radius in one substep, or they could
+		// skip through thin colliders
+		maxSpeed := fluid.ParticleRadius / h
+		for i := 0; i < fluidSubsteps; i++ {
+			grid.clear()
+			for j, p := range fluid.Particles {
+				grid.insert(j, p.Pos)
+			}
+			fluidDensities(fluid, grid)
+			fluidForces(fluid, grid, accel, h)
+
+			for j := range fluid.Particles {
+				p := &fluid.Particles[j]
+				p.Vel.AddUpdate(accel[j].Add(gravity).Mult(h))
+				if speed := p.Vel.Magnitude(); speed > maxSpeed {
+					p.Vel.MultUpdate(maxSpeed / speed)
+				}
+				from := p.Pos
+				p.Pos.AddUpdate(p.Vel.Mult(h))
+				collidePointMass(&p.Pos, &p.Vel, from, invMass, fluid.ParticleRadius, fluid.Friction, nearby)
+			}
+		}
+
+		if entry.HasComponent(components.Transform) {
+			min, max := fluid.Bounds()
+			components.Transform.Get(entry).Pos = min.Add(max).Mult(0.5)
+		}
+	}
+}
+
+// fluidDensities sums the poly6 kernel over each particle's neighbours and derives its
+// pressure. Pressure never goes negative, so sparse particles do not clump together.
+func fluidDensities(fluid *components.FluidData, grid *spatialHash) {
+	r := fluid.SmoothingRadius
+	r2 := r * r
+	poly6 := 4 / (math.Pi * math.Pow(r, 8))
+	for i := range fluid.Particles {
+		p := &fluid.Particles[i]
+		density := 0.0
+		grid.near(p.Pos, func(j int) {
+			d := p.Pos.Add(fluid.Particles[j].Pos.Mult(-1))
+			if q := r2 - d.SquareMagnitude(); q > 0 {
+				density += q * q * q
+			}
+		})
+		p.Density = fluid.ParticleMass * poly6 * density
+		p.Pressure = math.Max(fluid.Stiffness*(p.Density-fluid.RestDensity), 0)
+	}
+}
+
+// fluidForces fills accel with the pressure acceleration of each particle and applies
+// viscosity straight to the velocities. Viscosity pulls a particle towards the kernel
+// weighted mean velocity of its neighbours, capped so thick fluids stay stable.
+func fluidForces(fluid *components.FluidData, grid *spatialHash, accel []Vec2.Vec2, h float64) {
+	r := fluid.SmoothingRadius
+	spiky := 30 / (math.Pi * math.Pow(r, 5))
+	laplacian := 40 / (math.Pi * math.Pow(r, 5))
+	m := fluid.ParticleMass
+
+	meanVel := make([]Vec2.Vec2, len(fluid.Particles))
+	weight := make([]float64, len(fluid.Particles))
+	for i := range fluid.Particles {
+		p := &fluid.Particles[i]
+		var a Vec2.Vec2
+		grid.near(p.Pos, func(j int) {
+			if j == i {
+				return
+			}
+			q := &fluid.Particles[j]
+			d := p.Pos.Add(q.Pos.Mult(-1))
+			dist := d.Magnitude()
+			if dist >= r {
+				return
+			}
+			if dist < 1e-9 {
+				// Coincident particles have no direction to push along, so pick one
+				d, dist = Vec2.Vec2{X: float64(i - j), Y: 0}, 1e-9
+			}
+			// Symmetric pressure term, so pairs push each other equally
+			push := m * (p.Pressure/(p.Density*p.Density) + q.Pressure/(q.Density*q.Density)) * spiky * (r - dist) * (r - dist)
+			a.AddUpdate(d.Mult(push / dist))
+
+			w := m / q.Density * laplacian * (r - dist)
+			meanVel[i].AddUpdate(q.Vel.Mult(w))
+			weight[i] += w
+		})
+		accel[i] = a
+	}
+
+	for i := range fluid.Particles {
+		if weight[i] <= 0 {
+			continue
+		}
+		p := &fluid.Particles[i]
+		blend := math.Min(fluid.Viscosity*h*weight[i], 1)
+		mean := meanVel[i].Mult(1 / weight[i])
+		p.Vel.AddUpdate(mean.Add(p.Vel.Mult(-1)).Mult(blend))
+	}
+}
+
+// metaballShaderSource cuts the blended blob field at a threshold and fills it with the
+// fluid colour, lightening the edge a little so the surface reads
+const metaballShaderSource = `//kage:unit pixels
+package main
+
+var Color vec4
+var Threshold float
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	field := imageSrc0At(srcPos).a
+	inside := smoothstep(Threshold, Threshold+0.04, field)
+	edge := 1 - smoothstep(Threshold, Threshold+0.25, field)
+	rgb := mix(Color.rgb, vec3(Color.a), 0.35*edge)
+	return vec4(rgb, Color.a) * inside
+}
+`
+
+var (
+	metaballShader  *ebiten.Shader
+	metaballBlob    *ebiten.Image
+	metaballBuffers = map[image.Point]*ebiten.Image{}
+)
+
+// metaballBlobSize is the edge of the falloff texture stamped for each particle
+const metaballBlobSize = 32
+
+// metaballResources compiles the threshold shader and builds the falloff texture on first use
+func metaballResources() (*ebiten.Shader, *ebiten.Image) {
+	if metaballShader == nil {
+		shader, err := ebiten.NewShader([]byte(metaballShaderSource))
+		if err != nil {
+			panic(err)
+		}
+		metaballShader = shader
+
+		pixels := make([]byte, 4*metaballBlobSize*metaballBlobSize)
+		half := float64(metaballBlobSize) / 2
+		for y := 0; y < metaballBlobSize; y++ {
+			for x := 0; x < metaballBlobSize; x++ {
+				dx, dy := (float64(x)+0.5-half)/half, (float64(y)+0.5-half)/half
+				f := math.Max(1-(dx*dx+dy*dy), 0)
+				a := byte(255 * f * f)
+				i := 4 * (y*metaballBlobSize + x)
+				pixels[i], pixels[i+1], pixels[i+2], pixels[i+3] = a, a, a, a
+			}
+		}
+		metaballBlob = ebiten.NewImage(metaballBlobSize, metaballBlobSize)
+		metaballBlob.WritePixels(pixels)
+	}
+	return metaballShader, metaballBlob
+}
+
+// drawFluids draws every fluid seen by the camera. Liquids add a soft blob per particle
+// into an offscreen field that a shader cuts into a smooth surface; grains are plain squares.
+func drawFluids(e *ecs.ECS, camera *donburi.Entry, screen_camera *ebiten.Image) {
+	camera_tr := components.Transform.Get(camera)
+	camera_comp := components.Camera.Get(camera)
+	view := screen_camera.Bounds()
+
+	for entry := range components.Fluid.Iter(e.World) {
+		if !camera_comp.SeesEntity(entry) {
+			continue
+		}
+		fluid := components.Fluid.Get(entry)
+		if len(fluid.Particles) == 0 {
+			continue
+		}
+		if fluid.Render == components.FluidGrains {
+			drawFluidGrains(fluid, camera_tr.Pos, camera_comp, screen_camera)
+			continue
+		}
+
+		shader, blob := metaballResources()
+		buffer := metaballBuffers[view.Size()]
+		if buffer == nil {
+			buffer = ebiten.NewImage(view.Dx(), view.Dy())
+			metaballBuffers[view.Size()] = buffer
+		}
+		buffer.Clear()
+
+		// Blobs reach one smoothing radius, so neighbouring particles always merge
+		radius := fluid.SmoothingRadius
+		var vertices []ebiten.Vertex
+		var indices []uint16
+		flush := func() {
+			if len(indices) > 0 {
+				buffer.DrawTriangles(vertices, indices, blob, &ebiten.DrawTrianglesOptions{Blend: ebiten.BlendLighter})
+			}
+			vertices, indices = vertices[:0], indices[:0]
+		}
+		for _, p := range fluid.Particles {
+			s := camera_comp.WorldToScreen(camera_tr.Pos, p.Pos).Add(Vec2.Vec2{X: -float64(view.Min.X), Y: -float64(view.Min.Y)})
+			hx, hy := radius*camera_comp.Zoom.X, radius*camera_comp.Zoom.Y
+			if s.X+hx < 0 || s.Y+hy < 0 || s.X-hx > float64(view.Dx()) || s.Y-hy > float64(view.Dy()) {
+				continue
+			}
+			if len(vertices)+4 > maxBatchVertices {
+				flush()
+			}
+			base := uint16(len(vertices))
+			for _, corner := range [4][2]float64{{-1, -1}, {1, -1}, {1, 1}, {-1, 1}} {
+				vertices = append(vertices, ebiten.Vertex{
+					DstX: float32(s.X + corner[0]*hx), DstY: float32(s.Y + corner[1]*hy),
+					SrcX: float32((corner[0] + 1) / 2 * metaballBlobSize), SrcY: float32((corner[1] + 1) / 2 * metaballBlobSize),
+					ColorR: 1, ColorG: 1, ColorB: 1, ColorA: 1,
+				})
+			}
+			indices = append(indices, base, base+1, base+2, base, base+2, base+3)
+		}
+		flush()
+
+		c := fluid.Color
+		op := &ebiten.DrawRectShaderOptions{}
+		op.GeoM.Translate(float64(view.Min.X), float64(view.Min.Y))
+		op.Images[0] = buffer
+		op.Uniforms = map[string]any{
+			"Color":     []float32{float32(c.R) / 0xff, float32(c.G) / 0xff, float32(c.B) / 0xff, float32(c.A) / 0xff},
+			"Threshold": float32(0.5),
+		}
+		screen_camera.DrawRectShader(view.Dx(), view.Dy(), shader, op)
+	}
+}
+
+// drawFluidGrains draws one square per particle, shading each by its speed. The colours are
+// premultiplied, as color.RGBA is.
+func drawFluidGrains(fluid *components.FluidData, camPos Vec2.Vec2, camera_comp *components.CameraData, screen_camera *ebiten.Image) {
+	view := screen_camera.Bounds()
+	var vertices []ebiten.Vertex
+	var indices []uint16
+	flush := func() {
+		if len(indices) > 0 {
+			fillTriangles(screen_camera, vertices, indices, ebiten.DrawTrianglesOptions{})
+		}
+		vertices, indices = vertices[:0], indices[:0]
+	}
+	half := fluid.ParticleRadius * 1.2
+	for _, p := range fluid.Particles {
+		s := camera_comp.WorldToScreen(camPos, p.Pos)
+		hx, hy := half*camera_comp.Zoom.X, half*camera_comp.Zoom.Y
+		if s.X+hx < float64(view.Min.X) || s.X-hx > float64(view.Max.X) ||
+			s.Y+hy < float64(view.Min.Y) || s.Y-hy > float64(view.Max.Y) {
+			continue
+		}
+		if len(vertices)+4 > maxBatchVertices {
+			flush()
+		}
+		// Moving grains catch the light
+		shade := 0.85 + 0.15*math.Min(p.Vel.Magnitude()/300, 1)
+		vertices, indices = appendFilledQuad(vertices, indices, s, hx, hy, scaleRGB(fluid.Color, shade))
+	}
+	flush()
+}
+
+// scaleRGB brightens or darkens a colour, keeping it a valid premultiplied colour
+func scaleRGB(c color.RGBA, f float64) color.RGBA {
+	scale := func(v uint8) uint8 { return uint8(math.Min(float64(v)*f, float64(c.A))) }
+	return color.RGBA{R: scale(c.R), G: scale(c.G), B: scale(c.B), A: c.A}
+}
@@ -0,0 +1,204 @@
+package systems
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"physengine/components"
+	Vec2 "physengine/helpers/vec2"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/yohamta/donburi"
+	"github.com/yohamta/donburi/ecs"
+)
+
+// UpdateFracture shatters breakable bodies that a contact of the last collision step hit
+// harder than their strength. It must run after the collision step.
+func UpdateFracture(e *ecs.ECS) {
+	resolver_entry, ok := components.CollisionResolverComponent.First(e.World)
+	if !ok {
+		return
+	}
+
+	// The strongest hit on each breakable body
+	hits := map[*donburi.Entry]components.ContactData{}
+	var order []*donburi.Entry
+	for _, contact := range components.CollisionResolverComponent.Get(resolver_entry).Contacts {
+		for _, entry := range []*donburi.Entry{contact.EntryA, contact.EntryB} {
+			if entry == nil || !entry.HasComponent(components.Breakable) {
+				continue
+			}
+			if contact.Impulse < components.Breakable.Get(entry).Strength {
+				continue
+			}
+			strongest, seen := hits[entry]
+			if !seen {
+				order = append(order, entry)
+			}
+			if !seen || contact.Impulse > strongest.Impulse {
+				hits[entry] = contact
+			}
+		}
+	}
+
+	for _, entry := range order {
+		if entry.Valid() {
+			fractureBody(e, entry, hits[entry].Point)
+		}
+	}
+}
+
+// fractureBody replaces a body with fragments broken around a world-space impact point.
+// Fragments move with the body, so each takes the velocity of the point it came from.
+func fractureBody(e *ecs.ECS, entry *donburi.Entry, impact Vec2.Vec2) {
+	breakable := *components.Breakable.Get(entry)
+	tr := *components.Transform.Get(entry)
+	local := components.RotatePoint(impact.Add(tr.Pos.Mult(-1)), -tr.Rot)
+	fragments := components.FractureShapes(components.BodyShapes(entry), local, breakable.Fragments)
+	if len(fragments) < 2 {
+		// Nothing to split, so stop trying
+		entry.RemoveComponent(components.Breakable)
+		return
+	}
+
+	var material components.MaterialData
+	if entry.HasComponent(components.MaterialComponent) {
+		material = *components.MaterialComponent.Get(entry)
+	}
+	density := material.Density
+	if density <= 0 {
+		density = 0.001
+	}
+	body := newRigidBody(entry)
+
+	for _, shapes := range fragments {
+		mass, _, centroid := components.ComputeMassProperties(shapes, density)
+		if mass <= 0 {
+			continue
+		}
+		sprite, hasSprite := cutSprite(entry, shapes, centroid)
+		area := 0.0
+		for i := range shapes {
+			area += shapes[i].Area()
+			shapes[i].Offset = centroid.Mult(-1)
+		}
+
+		fragment := e.World.Entry(e.World.Create(components.MaterialComponent, components.Transform, components.CompoundCollider, components.MassComponent, components.Velocity, components.AngularVelocity, components.Torque))
+		components.MaterialComponent.SetValue(fragment, material)
+		components.CompoundCollider.Get(fragment).Shapes = shapes
+		ftr := components.Transform.Get(fragment)
+		offset := components.RotatePoint(centroid, tr.Rot)
+		ftr.Pos = tr.Pos.Add(offset)
+		ftr.Rot = tr.Rot
+		ftr.Scale = tr.Scale
+		components.SetMassFromShapes(fragment, density)
+		components.Velocity.Get(fragment).Velocity = body.velocityAt(offset)
+		components.SetAngularVelocity(fragment, *body.angVel)
+
+		if hasSprite {
+			fragment.AddComponent(components.Sprite)
+			components.Sprite.SetValue(fragment, sprite)
+		}
+		if entry.HasComponent(components.ZIndex) {
+			fragment.AddComponent(components.ZIndex)
+			components.ZIndex.SetValue(fragment, *components.ZIndex.Get(entry))
+		}
+		if entry.HasComponent(components.RenderLayer) {
+			fragment.AddComponent(components.RenderLayer)
+			components.RenderLayer.SetValue(fragment, *components.RenderLayer.Get(entry))
+		}
+		if breakable.Depth > 0 && area >= breakable.MinArea {
+			next := breakable
+			next.Depth--
+			fragment.AddComponent(components.Breakable)
+			components.Breakable.SetValue(fragment, next)
+		}
+	}
+	e.World.Remove(entry.Entity())
+}
+
+// cutSprite copies the part of a body's Sprite or Drawable under the given body-space
+// shapes into a new image, and returns a sprite that draws it in place on a fragment whose
+// centre is at centroid in the body's space
+func cutSprite(entry *donburi.Entry, shapes []components.Shape, centroid Vec2.Vec2) (components.SpriteData, bool) {
+	var src *ebiten.Image
+	var source image.Rectangle
+	var pivot Vec2.Vec2
+	var flipX, flipY bool
+	cut := components.SpriteData{Alpha: 1, Tint: color.RGBA{R: 255, G: 255, B: 255, A: 255}}
+	switch {
+	case entry.HasComponent(components.Sprite):
+		sprite := components.Sprite.Get(entry)
+		src, source, pivot = sprite.Image, sprite.Source(), sprite.PivotOffset
+		flipX, flipY = sprite.FlipX, sprite.FlipY
+		cut.Tint, cut.Alpha = sprite.Tint, sprite.Alpha
+	case entry.HasComponent(components.Drawable):
+		src = components.Drawable.Get(entry).Sprite
+		if src != nil {
+			source = src.Bounds()
+		}
+	}
+	if src == nil || source.Empty() {
+		return components.SpriteData{}, false
+	}
+
+	scale := components.Transform.Get(entry).Scale
+	if scale.X == 0 || scale.Y == 0 {
+		scale = Vec2.Vec2{X: 1, Y: 1}
+	}
+	w, h := float64(source.Dx()), float64(source.Dy())
+	// Where a body-space point lands on the drawn sprite, in pixels from its top-left corner
+	onSprite := func(p Vec2.Vec2) Vec2.Vec2 {
+		return Vec2.Vec2{X: p.X/scale.X + w/2 + pivot.X, Y: -p.Y/scale.Y + h/2 + pivot.Y}
+	}
+
+	var polygons [][]Vec2.Vec2
+	minX, minY, maxX, maxY := math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)
+	for _, s := range shapes {
+		polygon := make([]Vec2.Vec2, len(s.Vertices))
+		for i, v := range s.Vertices {
+			polygon[i] = onSprite(s.Offset.Add(components.RotatePoint(v, s.Rot)))
+			minX, minY = math.Min(minX, polygon[i].X), math.Min(minY, polygon[i].Y)
+			maxX, maxY = math.Max(maxX, polygon[i].X), math.Max(maxY, polygon[i].Y)
+		}
+		polygons = append(polygons, polygon)
+	}
+	bounds := image.Rect(int(math.Floor(minX)), int(math.Floor(minY)), int(math.Ceil(maxX)), int(math.Ceil(maxY))).
+		Intersect(image.Rect(0, 0, source.Dx(), source.Dy()))
+	if bounds.Empty() {
+		return components.SpriteData{}, false
+	}
+
+	img := ebiten.NewImage(bounds.Dx(), bounds.Dy())
+	for _, polygon := range polygons {
+		vertices := make([]ebiten.Vertex, len(polygon))
+		for i, p := range polygon {
+			// Bake flips into the cut so the fragment draws unflipped
+			u, v := p.X, p.Y
+			if flipX {
+				u = w - u
+			}
+			if flipY {
+				v = h - v
+			}
+			vertices[i] = ebiten.Vertex{
+				DstX: float32(p.X) - float32(bounds.Min.X), DstY: float32(p.Y) - float32(bounds.Min.Y),
+				SrcX: float32(u) + float32(source.Min.X), SrcY: float32(v) + float32(source.Min.Y),
+				ColorR: 1, ColorG: 1, ColorB: 1, ColorA: 1,
+			}
+		}
+		indices := make([]uint16, 0, 3*(len(polygon)-2))
+		for i := 1; i+1 < len(polygon); i++ {
+			indices = append(indices, 0, uint16(i), uint16(i+1))
+		}
+		img.DrawTriangles(vertices, indices, src, &ebiten.DrawTrianglesOptions{AntiAlias: true})
+	}
+
+	cut.Image = img
+	cut.Loop = true
+	cut.PivotOffset = Vec2.Vec2{
+		X: w/2 + pivot.X + centroid.X/scale.X - float64(bounds.Min.X) - float64(bounds.Dx())/2,
+		Y: h/2 + pivot.Y - centroid.Y/scale.Y - float64(bounds.Min.Y) - float64(bounds.Dy())/2,
+	}
+	return cut, true
+}
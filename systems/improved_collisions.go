@@ -4,10 +4,10 @@ import (
 	"math"
 	"physengine/components"
 	Vec2 "physengine/helpers/vec2"
+	"time"
 
 	"github.com/yohamta/donburi"
 	"github.com/yohamta/donburi/ecs"
-	"github.com/yohamta/donburi/filter"
 )
 
 func UpdateImprovedCollisions(e *ecs.ECS) {
@@ -16,116 +16,84 @@ func UpdateImprovedCollisions(e *ecs.ECS) {
 		return
 	}
 	resolver_comp := components.CollisionResolverComponent.Get(resolver_entry)
+	start := time.Now()
 
 	// Update the physics objects list dynamically
-	query := donburi.NewQuery(filter.Or(filter.Contains(components.CircleCollider), filter.Contains(components.AABB_Component)))
+	query := donburi.NewQuery(components.ColliderFilter())
 	resolver_comp.Physobs = nil
 	for phys_entry := range query.Iter(e.World) {
 		resolver_comp.Physobs = append(resolver_comp.Physobs, phys_entry)
 	}
 
-	for num1 := 0; num1 < len(resolver_comp.Physobs); num1++ {
-		for num2 := num1 + 1; num2 < len(resolver_comp.Physobs); num2++ {
-			ResolveImprovedCollisions(resolver_comp.Physobs[num1], resolver_comp.Physobs[num2])
+	resolver_comp.Contacts = resolver_comp.Contacts[:0]
+	resolver_comp.PairCount = 0
+	connected := components.ConnectedPairs(e.World)
+	sweepPairs(broadphaseProxies(resolver_comp), func(a, b *components.BroadphaseProxy) {
+		if connected[components.NewJointPair(a.Entry.Entity(), b.Entry.Entity())] {
+			return
 		}
-	}
+		resolver_comp.PairCount++
+		if contact, ok := resolveShapePair(a.Entry, b.Entry, a.Shape, b.Shape); ok {
+			resolver_comp.Contacts = append(resolver_comp.Contacts, contact)
+		}
+	})
+	resolver_comp.StepDuration = time.Since(start)
 }
 
-func ResolveImprovedCollisions(e1, e2 *donburi.Entry) {
-	// Circle vs Circle (rotation doesn't affect circle collision)
-	if e1.HasComponent(components.CircleCollider) && e2.HasComponent(components.CircleCollider) {
-		if components.CirclesCollide(e1, e2) {
-			tr1 := components.Transform.Get(e1)
-			tr2 := components.Transform.Get(e2)
-			crcl1 := components.CircleCollider.Get(e1)
-			crcl2 := components.CircleCollider.Get(e2)
-			mat1 := components.MaterialComponent.Get(e1)
-			mat2 := components.MaterialComponent.Get(e2)
-
-			// Calculate collision normal (from e1 to e2)
-			normal := tr2.Pos.Add(tr1.Pos.Mult(-1))
-			distance := normal.Magnitude()
-
-			// Normalize the normal vector with better numerical stability
-			if distance > 0.001 {
-				normal = normal.Mult(1.0 / distance)
-			} else {
-				// If circles are exactly on top of each other, use a default normal
-				normal = Vec2.Vec2{X: 1, Y: 0}
+// ResolveImprovedCollisions resolves every overlapping pair of shapes of two bodies
+// and returns the contacts it handled
+func ResolveImprovedCollisions(e1, e2 *donburi.Entry) []components.ContactData {
+	var contacts []components.ContactData
+	shapes1 := components.WorldShapes(e1)
+	shapes2 := components.WorldShapes(e2)
+	for _, s1 := range shapes1 {
+		min1, max1 := s1.Bounds()
+		for _, s2 := range shapes2 {
+			min2, max2 := s2.Bounds()
+			if max1.X < min2.X || max2.X < min1.X || max1.Y < min2.Y || max2.Y < min1.Y {
+				continue
 			}
-
-			// Calculate collision point (weighted by radius)
-			totalRadius := crcl1.Radius + crcl2.Radius
-			ratio1 := crcl2.Radius / totalRadius
-			ratio2 := crcl1.Radius / totalRadius
-			collisionPoint := Vec2.Vec2{
-				X: tr1.Pos.X*ratio2 + tr2.Pos.X*ratio1,
-				Y: tr1.Pos.Y*ratio2 + tr2.Pos.Y*ratio1,
+			if contact, ok := resolveShapePair(e1, e2, s1, s2); ok {
+				contacts = append(contacts, contact)
 			}
-
-			var j float64 = ResolveWithImprovedAngularImpulse(e1, e2, normal, collisionPoint, mat1.Restitution, mat2.Restitution)
-			penetration := totalRadius - distance
-			ImprovedPositionalCorrection(e1, e2, normal, penetration, 0.2)
-			ResolveImprovedFriction(e1, e2, normal, collisionPoint, j)
 		}
 	}
 
-	// Rotated AABB vs Rotated AABB
-	if e1.HasComponent(components.AABB_Component) && e2.HasComponent(components.AABB_Component) {
-		colliding, normal, penetration := components.RotatedAABBvsAABB(e1, e2)
-		if colliding {
-			mat1 := components.MaterialComponent.Get(e1)
-			mat2 := components.MaterialComponent.Get(e2)
-
-			// Calculate collision point (center of overlap)
-			tr1 := components.Transform.Get(e1)
-			tr2 := components.Transform.Get(e2)
-			collisionPoint := Vec2.Vec2{
-				X: (tr1.Pos.X + tr2.Pos.X) / 2,
-				Y: (tr1.Pos.Y + tr2.Pos.Y) / 2,
-			}
+	return contacts
+}
 
-			var j float64 = ResolveWithImprovedAngularImpulse(e1, e2, normal, collisionPoint, mat1.Restitution, mat2.Restitution)
-			ImprovedPositionalCorrection(e1, e2, normal, penetration, 0.2)
-			ResolveImprovedFriction(e1, e2, normal, collisionPoint, j)
-		}
+// resolveShapePair runs the narrowphase on one shape of each body and applies the impulses
+func resolveShapePair(e1, e2 *donburi.Entry, s1, s2 components.WorldShape) (components.ContactData, bool) {
+	contact, colliding := components.CollideShapes(s1, s2)
+	if !colliding {
+		return components.ContactData{}, false
 	}
+	restitution := components.CombineRestitution(materialOf(e1), materialOf(e2))
 
-	// Circle vs Rotated AABB
-	if (e1.HasComponent(components.AABB_Component) && e2.HasComponent(components.CircleCollider)) ||
-		(e2.HasComponent(components.AABB_Component) && e1.HasComponent(components.CircleCollider)) {
-		var box *donburi.Entry
-		var circle *donburi.Entry
-		if e1.HasComponent(components.CircleCollider) {
-			circle = e1
-			box = e2
-		} else {
-			circle = e2
-			box = e1
-		}
-
-		colliding, normal, penetration := components.RotatedCirclevsAABB(circle, box)
-		if colliding {
-			mat1 := components.MaterialComponent.Get(e1)
-			mat2 := components.MaterialComponent.Get(e2)
-
-			// Calculate collision point
-			circleTr := components.Transform.Get(circle)
-			boxTr := components.Transform.Get(box)
-			collisionPoint := Vec2.Vec2{
-				X: (circleTr.Pos.X + boxTr.Pos.X) / 2,
-				Y: (circleTr.Pos.Y + boxTr.Pos.Y) / 2,
-			}
+	var j float64 = ResolveImprovedNormalImpulse(e1, e2, contact.Normal, contact.Point, restitution)
+	ImprovedPositionalCorrection(e1, e2, contact.Normal, contact.Penetration, 0.2)
+	ResolveImprovedFriction(e1, e2, contact.Normal, contact.Point, j)
+	return components.ContactData{EntryA: e1, EntryB: e2, Point: contact.Point, Normal: contact.Normal, Penetration: contact.Penetration, Impulse: j}, true
+}
 
-			var j float64 = ResolveWithImprovedAngularImpulse(box, circle, normal, collisionPoint, mat1.Restitution, mat2.Restitution)
-			ImprovedPositionalCorrection(box, circle, normal, penetration, 0.2)
-			ResolveImprovedFriction(e1, e2, normal, collisionPoint, j)
-		}
+// materialOf returns the material of an entity, or nil if it has none
+func materialOf(entry *donburi.Entry) *components.MaterialData {
+	if !entry.HasComponent(components.MaterialComponent) {
+		return nil
 	}
+	return components.MaterialComponent.Get(entry)
 }
 
-// ResolveWithImprovedAngularImpulse resolves collision with improved numerical stability
+// ResolveWithImprovedAngularImpulse resolves collision with improved numerical stability,
+// mixing the two restitutions the way materials without a combine mode are
 func ResolveWithImprovedAngularImpulse(e1, e2 *donburi.Entry, normal Vec2.Vec2, collisionPoint Vec2.Vec2, res1, res2 float64) float64 {
+	restitution := components.CombineRestitution(&components.MaterialData{Restitution: res1}, &components.MaterialData{Restitution: res2})
+	return ResolveImprovedNormalImpulse(e1, e2, normal, collisionPoint, restitution)
+}
+
+// ResolveImprovedNormalImpulse applies the normal impulse of a contact with an already
+// combined restitution and returns its magnitude
+func ResolveImprovedNormalImpulse(e1, e2 *donburi.Entry, normal Vec2.Vec2, collisionPoint Vec2.Vec2, restitution float64) float64 {
 	vel1 := components.Velocity.Get(e1)
 	vel2 := components.Velocity.Get(e2)
 	angVel1 := components.AngularVelocity.Get(e1)
@@ -136,6 +104,9 @@ func ResolveWithImprovedAngularImpulse(e1, e2 *donburi.Entry, normal Vec2.Vec2,
 	if vel1 == nil || vel2 == nil || m1 == nil || m2 == nil {
 		return 0
 	}
+	// Locked axes and frozen rotation make a body infinitely heavy against them
+	invI1 := components.InverseInertiaOf(e1)
+	invI2 := components.InverseInertiaOf(e2)
 
 	// Calculate relative velocity at collision point
 	tr1 := components.Transform.Get(e1)
@@ -170,20 +141,19 @@ func ResolveWithImprovedAngularImpulse(e1, e2 *donburi.Entry, normal Vec2.Vec2,
 
 	// Only resolve if objects are moving towards each other
 	if velAlongNormal <= -0.001 { // Small threshold to prevent numerical issues
-		e := math.Min(res1, res2)
-		j := -(1 + e) * velAlongNormal
+		j := -(1 + restitution) * velAlongNormal
 
 		// Calculate impulse denominator including angular terms
-		denominator := m1.InverseMass + m2.InverseMass
+		denominator := components.InverseMassAlong(e1, normal) + components.InverseMassAlong(e2, normal)
 
 		// Add angular terms to denominator with improved numerical stability
-		if angVel1 != nil && m1.InverseInertia > 0 {
+		if angVel1 != nil && invI1 > 0 {
 			cross1 := r1.X*normal.Y - r1.Y*normal.X
-			denominator += cross1 * cross1 * m1.InverseInertia
+			denominator += cross1 * cross1 * invI1
 		}
-		if angVel2 != nil && m2.InverseInertia > 0 {
+		if angVel2 != nil && invI2 > 0 {
 			cross2 := r2.X*normal.Y - r2.Y*normal.X
-			denominator += cross2 * cross2 * m2.InverseInertia
+			denominator += cross2 * cross2 * invI2
 		}
 
 		// Prevent division by zero and clamp impulse
@@ -202,17 +172,17 @@ func ResolveWithImprovedAngularImpulse(e1, e2 *donburi.Entry, normal Vec2.Vec2,
 
 			// Apply linear impulse
 			impulse := normal.Mult(j)
-			vel1.Velocity = vel1.Velocity.Add(impulse.Mult(-m1.InverseMass))
-			vel2.Velocity = vel2.Velocity.Add(impulse.Mult(m2.InverseMass))
+			vel1.Velocity = vel1.Velocity.Add(components.MaskLockedAxes(e1, impulse.Mult(-m1.InverseMass)))
+			vel2.Velocity = vel2.Velocity.Add(components.MaskLockedAxes(e2, impulse.Mult(m2.InverseMass)))
 
 			// Apply angular impulse with improved stability
-			if angVel1 != nil && m1.InverseInertia > 0 {
+			if angVel1 != nil && invI1 > 0 {
 				cross1 := r1.X*normal.Y - r1.Y*normal.X
-				angVel1.AngularVelocity -= j * cross1 * m1.InverseInertia
+				angVel1.AngularVelocity -= j * cross1 * invI1
 			}
-			if angVel2 != nil && m2.InverseInertia > 0 {
+			if angVel2 != nil && invI2 > 0 {
 				cross2 := r2.X*normal.Y - r2.Y*normal.X
-				angVel2.AngularVelocity += j * cross2 * m2.InverseInertia
+				angVel2.AngularVelocity += j * cross2 * invI2
 			}
 
 			return j
@@ -230,12 +200,14 @@ func ResolveImprovedFriction(e1, e2 *donburi.Entry, normal Vec2.Vec2, collisionP
 	angVel2 := components.AngularVelocity.Get(e2)
 	m1 := components.MassComponent.Get(e1)
 	m2 := components.MassComponent.Get(e2)
-	mat1 := components.MaterialComponent.Get(e1)
-	mat2 := components.MaterialComponent.Get(e2)
+	mat1 := materialOf(e1)
+	mat2 := materialOf(e2)
 
 	if vel1 == nil || vel2 == nil || m1 == nil || m2 == nil {
 		return
 	}
+	invI1 := components.InverseInertiaOf(e1)
+	invI2 := components.InverseInertiaOf(e2)
 
 	tr1 := components.Transform.Get(e1)
 	tr2 := components.Transform.Get(e2)
@@ -274,28 +246,27 @@ func ResolveImprovedFriction(e1, e2 *donburi.Entry, normal Vec2.Vec2, collisionP
 	jt := -Vec2.DotProduct(relativeVel, tangent)
 
 	// Calculate impulse denominator including angular terms
-	denominator := m1.InverseMass + m2.InverseMass
+	denominator := components.InverseMassAlong(e1, tangent) + components.InverseMassAlong(e2, tangent)
 
-	if angVel1 != nil && m1.InverseInertia > 0 {
+	if angVel1 != nil && invI1 > 0 {
 		cross1 := r1.X*tangent.Y - r1.Y*tangent.X
-		denominator += cross1 * cross1 * m1.InverseInertia
+		denominator += cross1 * cross1 * invI1
 	}
-	if angVel2 != nil && m2.InverseInertia > 0 {
+	if angVel2 != nil && invI2 > 0 {
 		cross2 := r2.X*tangent.Y - r2.Y*tangent.X
-		denominator += cross2 * cross2 * m2.InverseInertia
+		denominator += cross2 * cross2 * invI2
 	}
 
 	if denominator > 0.001 {
 		jt /= denominator
 
-		// Apply friction limits with improved stability
-		mu := math.Sqrt(mat1.StaticFriction*mat1.StaticFriction + mat2.StaticFriction*mat2.StaticFriction)
+		// Apply friction limits using the materials' combine rules
+		mu, dynamicFriction := components.CombineFriction(mat1, mat2)
 		var frictionImpulse Vec2.Vec2
 
 		if math.Abs(jt) < j*mu {
 			frictionImpulse = tangent.Mult(jt)
 		} else {
-			dynamicFriction := math.Sqrt(mat1.DynamicFriction*mat1.DynamicFriction + mat2.DynamicFriction*mat2.DynamicFriction)
 			frictionImpulse = tangent.Mult(-j * dynamicFriction)
 		}
 
@@ -307,17 +278,17 @@ func ResolveImprovedFriction(e1, e2 *donburi.Entry, normal Vec2.Vec2, collisionP
 		}
 
 		// Apply linear friction impulse
-		vel1.Velocity.AddUpdate(frictionImpulse.Mult(-m1.InverseMass))
-		vel2.Velocity.AddUpdate(frictionImpulse.Mult(m2.InverseMass))
+		vel1.Velocity.AddUpdate(components.MaskLockedAxes(e1, frictionImpulse.Mult(-m1.InverseMass)))
+		vel2.Velocity.AddUpdate(components.MaskLockedAxes(e2, frictionImpulse.Mult(m2.InverseMass)))
 
 		// Apply angular friction impulse
-		if angVel1 != nil && m1.InverseInertia > 0 {
+		if angVel1 != nil && invI1 > 0 {
 			cross1 := r1.X*frictionImpulse.Y - r1.Y*frictionImpulse.X
-			angVel1.AngularVelocity -= cross1 * m1.InverseInertia
+			angVel1.AngularVelocity -= cross1 * invI1
 		}
-		if angVel2 != nil && m2.InverseInertia > 0 {
+		if angVel2 != nil && invI2 > 0 {
 			cross2 := r2.X*frictionImpulse.Y - r2.Y*frictionImpulse.X
-			angVel2.AngularVelocity += cross2 * m2.InverseInertia
+			angVel2.AngularVelocity += cross2 * invI2
 		}
 	}
 }
@@ -336,7 +307,7 @@ func ImprovedPositionalCorrection(e1, e2 *donburi.Entry, n Vec2.Vec2, penetratio
 	}
 
 	// Calculate correction with improved stability
-	totalInverseMass := m1.InverseMass + m2.InverseMass
+	totalInverseMass := components.InverseMassAlong(e1, n) + components.InverseMassAlong(e2, n)
 	if totalInverseMass < 0.001 {
 		return
 	}
@@ -350,6 +321,6 @@ func ImprovedPositionalCorrection(e1, e2 *donburi.Entry, n Vec2.Vec2, penetratio
 		correction = correction.Mult(maxCorrection)
 	}
 
-	components.ChangePos(e1, correction.Mult(-m1.InverseMass))
-	components.ChangePos(e2, correction.Mult(m2.InverseMass))
+	components.ChangePos(e1, components.MaskLockedAxes(e1, correction.Mult(-m1.InverseMass)))
+	components.ChangePos(e2, components.MaskLockedAxes(e2, correction.Mult(m2.InverseMass)))
 }
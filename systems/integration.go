@@ -0,0 +1,413 @@
+package systems
+
+import (
+	"math"
+	"physengine/components"
+	Vec2 "physengine/helpers/vec2"
+
+	"github.com/yohamta/donburi"
+	"github.com/yohamta/donburi/ecs"
+	"github.com/yohamta/donburi/filter"
+)
+
+// integratedBody is one body advanced by the integrator, with the inputs that stay
+// constant over the step
+type integratedBody struct {
+	entry   *donburi.Entry
+	invMass float64
+	invI    float64
+	force   Vec2.Vec2 // Applied force from the Force accumulator
+	torque  float64
+	gravity Vec2.Vec2 // Gravity acceleration after the body's gravity scale
+	limits  components.MotionLimitsData
+	pull    float64 // Attracting mass, 0 unless the body is an Attractor
+	soften  float64 // Softening length of the attractor
+}
+
+// integrationStep is everything that stays constant while one step is integrated
+type integrationStep struct {
+	bodies []integratedBody
+	fixed  []gravitySource // Attractors that are not integrated themselves
+	g      float64
+	theta  float64
+}
+
+// bodyState is the position and velocity of a body at one instant
+type bodyState struct {
+	pos    Vec2.Vec2
+	vel    Vec2.Vec2
+	rot    float64
+	angVel float64
+}
+
+// bodyDerivative is the rate of change of a bodyState
+type bodyDerivative struct {
+	vel    Vec2.Vec2
+	acc    Vec2.Vec2
+	angVel float64
+	angAcc float64
+}
+
+// UpdateIntegration advances every body through one step with the world's integrator,
+// turning accumulated forces and torques into velocity and velocity into position and
+// orientation. It runs after the collision and joint solvers, which only change velocities,
+// and records the energy error of the step in IntegrationStats. Bodies that stay at rest
+// for the world's SleepTime sleep and are skipped until something moves them.
+func UpdateIntegration(e *ecs.ECS) {
+	dt := stepSeconds(e)
+	if dt <= 0 {
+		return
+	}
+	world := components.GetPhysicsWorld(e.World)
+	integrator := components.IntegratorSemiImplicitEuler
+	if world != nil {
+		integrator = world.Integrator
+	}
+
+	step, states := gatherIntegratedBodies(e, world, dt)
+	before := step.energy(states)
+
+	var next []bodyState
+	switch integrator {
+	case components.IntegratorVelocityVerlet:
+		next = stepVelocityVerlet(step, states, dt)
+	case components.IntegratorRK4:
+		next = stepRK4(step, states, dt)
+	default:
+		next = stepSemiImplicitEuler(step, states, dt)
+	}
+
+	after := step.energy(next)
+	work := 0.0
+	for i, b := range step.bodies {
+		work += Vec2.DotProduct(b.force, next[i].pos.Add(states[i].pos.Mult(-1))) + b.torque*(next[i].rot-states[i].rot)
+
+		components.SetPos(b.entry, next[i].pos)
+		components.Rotate(b.entry, next[i].rot-states[i].rot)
+		if b.entry.HasComponent(components.Velocity) {
+			components.Velocity.Get(b.entry).Velocity = next[i].vel
+		}
+		if b.entry.HasComponent(components.AngularVelocity) {
+			components.SetAngularVelocity(b.entry, next[i].angVel)
+		}
+		clearAccumulators(b.entry)
+		updateSleep(world, b, next[i], dt)
+	}
+
+	if stats_entry, ok := components.IntegrationStats.First(e.World); ok {
+		stats := components.IntegrationStats.Get(stats_entry)
+		stats.Kinetic, stats.Potential = after.kinetic, after.potential
+		stats.Momentum, stats.AngularMomentum = after.momentum, after.angularMomentum
+		stats.StepDrift = after.kinetic + after.potential - before.kinetic - before.potential - work
+		stats.Drift += stats.StepDrift
+		stats.Steps++
+	}
+}
+
+// gatherIntegratedBodies damps and limits the velocities of every moving body and returns
+// the bodies with their starting states. Characters move themselves with move-and-slide.
+func gatherIntegratedBodies(e *ecs.ECS, world *components.PhysicsWorldData, dt float64) (*integrationStep, []bodyState) {
+	step := &integrationStep{}
+	if world != nil {
+		step.g, step.theta = world.GravitationalConstant, world.BarnesHutTheta
+	}
+	var states []bodyState
+	query := donburi.NewQuery(filter.And(
+		filter.Contains(components.Transform),
+		filter.Or(filter.Contains(components.Velocity), filter.Contains(components.AngularVelocity)),
+		filter.Not(filter.Contains(components.CharacterController)),
+	))
+	for entry := range query.Iter(e.World) {
+		dampLinearVelocity(entry, world, dt)
+		dampAngularVelocity(entry, world, dt)
+
+		b := integratedBody{entry: entry, force: components.GetForce(entry)}
+		if entry.HasComponent(components.Torque) {
+			b.torque = components.GetTorque(entry)
+		}
+		if entry.HasComponent(components.MotionLimits) {
+			b.limits = *components.MotionLimits.Get(entry)
+		}
+		// Without somewhere to keep a velocity the body cannot move that way at all
+		b.limits.LockX = b.limits.LockX || !entry.HasComponent(components.Velocity)
+		b.limits.LockY = b.limits.LockY || !entry.HasComponent(components.Velocity)
+		b.limits.FreezeRotation = b.limits.FreezeRotation || !entry.HasComponent(components.AngularVelocity)
+		if entry.HasComponent(components.MassComponent) && !components.IsStatic(entry) {
+			b.invMass = components.MassComponent.Get(entry).InverseMass
+			b.invI = components.InverseInertiaOf(entry)
+		}
+		if b.invMass > 0 && world != nil {
+			b.gravity = world.Gravity.Mult(components.GetGravityScale(entry))
+		}
+		if entry.HasComponent(components.Attractor) {
+			b.pull, b.soften = attractorMass(entry), components.Attractor.Get(entry).Softening
+		}
+
+		tr := components.Transform.Get(entry)
+		s := bodyState{pos: tr.Pos, rot: tr.Rot}
+		if entry.HasComponent(components.Velocity) {
+			s.vel = b.limits.LimitVelocity(components.Velocity.Get(entry).Velocity)
+		}
+		if entry.HasComponent(components.AngularVelocity) {
+			s.angVel = b.limits.LimitAngularVelocity(components.GetAngularVelocity(entry))
+		}
+		if stayAsleep(world, b, s, dt) {
+			holdStill(entry)
+			continue
+		}
+		step.bodies = append(step.bodies, b)
+		states = append(states, s)
+	}
+
+	// Attractors that do not move still pull, from where they are
+	query = donburi.NewQuery(filter.And(
+		filter.Contains(components.Attractor, components.Transform),
+		filter.Not(filter.Or(filter.Contains(components.Velocity), filter.Contains(components.AngularVelocity))),
+	))
+	for entry := range query.Iter(e.World) {
+		if mass := attractorMass(entry); mass > 0 {
+			step.fixed = append(step.fixed, gravitySource{
+				pos:       components.Transform.Get(entry).Pos,
+				mass:      mass,
+				softening: components.Attractor.Get(entry).Softening,
+				body:      -1,
+			})
+		}
+	}
+	return step, states
+}
+
+// stayAsleep reports whether a sleeping body stays asleep this step, which it does unless
+// its velocity or the forces on it would move it faster than the sleep speeds
+func stayAsleep(world *components.PhysicsWorldData, b integratedBody, s bodyState, dt float64) bool {
+	if !components.IsAsleep(b.entry) {
+		return false
+	}
+	if world == nil || world.SleepTime <= 0 {
+		components.WakeBody(b.entry)
+		return false
+	}
+	linear := s.vel.Magnitude() + b.force.Magnitude()*b.invMass*dt
+	angular := math.Abs(s.angVel) + math.Abs(b.torque)*b.invI*dt
+	if linear > world.SleepSpeed || angular > world.SleepAngularSpeed {
+		components.WakeBody(b.entry)
+		return false
+	}
+	return true
+}
+
+// updateSleep advances the rest timer of a dynamic body and puts it to sleep once it has
+// stayed below the sleep speeds for the world's SleepTime. Attractors never sleep.
+func updateSleep(world *components.PhysicsWorldData, b integratedBody, s bodyState, dt float64) {
+	if world == nil || world.SleepTime <= 0 || b.invMass <= 0 || b.entry.HasComponent(components.Attractor) {
+		return
+	}
+	if !b.entry.HasComponent(components.Sleep) {
+		b.entry.AddComponent(components.Sleep)
+	}
+	sleep := components.Sleep.Get(b.entry)
+	if s.vel.Magnitude() > world.SleepSpeed || math.Abs(s.angVel) > world.SleepAngularSpeed {
+		sleep.Idle = 0
+		return
+	}
+	sleep.Idle += dt
+	if sleep.Idle >= world.SleepTime {
+		sleep.Asleep = true
+		holdStill(b.entry)
+	}
+}
+
+// holdStill stops a sleeping body and drops what was applied to it
+func holdStill(entry *donburi.Entry) {
+	if entry.HasComponent(components.Velocity) {
+		components.Velocity.Get(entry).Velocity = Vec2.Vec2{}
+	}
+	if entry.HasComponent(components.AngularVelocity) {
+		components.SetAngularVelocity(entry, 0)
+	}
+	clearAccumulators(entry)
+}
+
+// clearAccumulators empties the Force and Torque accumulators for the next step
+func clearAccumulators(entry *donburi.Entry) {
+	if entry.HasComponent(components.Force) {
+		components.Force.Get(entry).Force = Vec2.Vec2{}
+	}
+	if entry.HasComponent(components.Torque) {
+		components.SetTorque(entry, 0)
+	}
+}
+
+// attractorMass returns the mass an Attractor pulls with
+func attractorMass(entry *donburi.Entry) float64 {
+	if mass := components.Attractor.Get(entry).Mass; mass > 0 {
+		return mass
+	}
+	if entry.HasComponent(components.MassComponent) {
+		return components.MassComponent.Get(entry).Mass
+	}
+	return 0
+}
+
+// sourceSet selects which attractors sources returns
+type sourceSet int
+
+const (
+	allSources      sourceSet = iota
+	mutualSources             // Dynamic attractors, which are pulled back by what they pull
+	externalSources           // Attractors that nothing pulls back
+)
+
+// sources returns a set of attractors, the integrated ones at the given states
+func (step *integrationStep) sources(states []bodyState, set sourceSet) []gravitySource {
+	var sources []gravitySource
+	for i, b := range step.bodies {
+		if b.pull <= 0 || (set == mutualSources && b.invMass <= 0) || (set == externalSources && b.invMass > 0) {
+			continue
+		}
+		sources = append(sources, gravitySource{pos: states[i].pos, mass: b.pull, softening: b.soften, body: i})
+	}
+	if set != mutualSources {
+		sources = append(sources, step.fixed...)
+	}
+	return sources
+}
+
+// derivatives returns the rate of change of every body's state. Applied forces are constant
+// over the step, while the pull of attractors follows the positions in states.
+func (step *integrationStep) derivatives(states []bodyState) []bodyDerivative {
+	var field *gravityField
+	if sources := step.sources(states, allSources); len(sources) > 0 && step.g != 0 {
+		field = newGravityField(sources, step.g, step.theta)
+	}
+	out := make([]bodyDerivative, len(step.bodies))
+	for i, b := range step.bodies {
+		acc := b.gravity.Add(b.force.Mult(b.invMass))
+		if field != nil && b.invMass > 0 {
+			pull, _ := field.at(states[i].pos, b.soften, i)
+			acc.AddUpdate(pull)
+		}
+		out[i] = bodyDerivative{
+			vel:    states[i].vel,
+			acc:    b.limits.Mask(acc),
+			angVel: states[i].angVel,
+		}
+		if !b.limits.FreezeRotation {
+			out[i].angAcc = b.torque * b.invI
+		}
+	}
+	return out
+}
+
+// advanceStates returns the states moved along the derivatives for a time h
+func advanceStates(states []bodyState, d []bodyDerivative, h float64) []bodyState {
+	out := make([]bodyState, len(states))
+	for i, s := range states {
+		out[i] = bodyState{
+			pos:    s.pos.Add(d[i].vel.Mult(h)),
+			vel:    s.vel.Add(d[i].acc.Mult(h)),
+			rot:    s.rot + d[i].angVel*h,
+			angVel: s.angVel + d[i].angAcc*h,
+		}
+	}
+	return out
+}
+
+func stepSemiImplicitEuler(step *integrationStep, states []bodyState, dt float64) []bodyState {
+	d := step.derivatives(states)
+	out := make([]bodyState, len(states))
+	for i, s := range states {
+		out[i].vel = s.vel.Add(d[i].acc.Mult(dt))
+		out[i].angVel = s.angVel + d[i].angAcc*dt
+		// Position moves with the new velocity, which is what keeps the method symplectic
+		out[i].pos = s.pos.Add(out[i].vel.Mult(dt))
+		out[i].rot = s.rot + out[i].angVel*dt
+	}
+	return out
+}
+
+func stepVelocityVerlet(step *integrationStep, states []bodyState, dt float64) []bodyState {
+	d0 := step.derivatives(states)
+	out := make([]bodyState, len(states))
+	for i, s := range states {
+		out[i].pos = s.pos.Add(s.vel.Mult(dt)).Add(d0[i].acc.Mult(dt * dt / 2))
+		out[i].rot = s.rot + s.angVel*dt + d0[i].angAcc*dt*dt/2
+		// Predicted velocities for forces that depend on them
+		out[i].vel = s.vel.Add(d0[i].acc.Mult(dt))
+		out[i].angVel = s.angVel + d0[i].angAcc*dt
+	}
+	d1 := step.derivatives(out)
+	for i, s := range states {
+		out[i].vel = s.vel.Add(d0[i].acc.Add(d1[i].acc).Mult(dt / 2))
+		out[i].angVel = s.angVel + (d0[i].angAcc+d1[i].angAcc)*dt/2
+	}
+	return out
+}
+
+func stepRK4(step *integrationStep, states []bodyState, dt float64) []bodyState {
+	k1 := step.derivatives(states)
+	k2 := step.derivatives(advanceStates(states, k1, dt/2))
+	k3 := step.derivatives(advanceStates(states, k2, dt/2))
+	k4 := step.derivatives(advanceStates(states, k3, dt))
+	sum := make([]bodyDerivative, len(states))
+	for i := range states {
+		sum[i] = bodyDerivative{
+			vel:    k1[i].vel.Add(k2[i].vel.Mult(2)).Add(k3[i].vel.Mult(2)).Add(k4[i].vel),
+			acc:    k1[i].acc.Add(k2[i].acc.Mult(2)).Add(k3[i].acc.Mult(2)).Add(k4[i].acc),
+			angVel: k1[i].angVel + 2*k2[i].angVel + 2*k3[i].angVel + k4[i].angVel,
+			angAcc: k1[i].angAcc + 2*k2[i].angAcc + 2*k3[i].angAcc + k4[i].angAcc,
+		}
+	}
+	return advanceStates(states, sum, dt/6)
+}
+
+// stepEnergy is the mechanical energy and momentum of the integrated bodies at one instant
+type stepEnergy struct {
+	kinetic         float64
+	potential       float64 // Uniform gravity and attractors
+	momentum        Vec2.Vec2
+	angularMomentum float64 // About the world origin
+}
+
+// energy returns the energy and momentum of the dynamic bodies at the given states
+func (step *integrationStep) energy(states []bodyState) stepEnergy {
+	var total stepEnergy
+	// Mutual attractor pairs are counted from both ends, so each end takes half
+	var mutual, external *gravityField
+	if step.g != 0 {
+		if sources := step.sources(states, mutualSources); len(sources) > 0 {
+			mutual = newGravityField(sources, step.g, step.theta)
+		}
+		if sources := step.sources(states, externalSources); len(sources) > 0 {
+			external = newGravityField(sources, step.g, step.theta)
+		}
+	}
+	for i, b := range step.bodies {
+		if b.invMass <= 0 {
+			continue
+		}
+		s := states[i]
+		mass := 1 / b.invMass
+		total.kinetic += 0.5 * mass * s.vel.SquareMagnitude()
+		total.angularMomentum += mass * Vec2.CrossProductVecVec(s.pos, s.vel)
+		if b.invI > 0 {
+			total.kinetic += 0.5 * s.angVel * s.angVel / b.invI
+			total.angularMomentum += s.angVel / b.invI
+		}
+		total.momentum.AddUpdate(s.vel.Mult(mass))
+		total.potential -= mass * Vec2.DotProduct(b.gravity, s.pos)
+
+		if mutual != nil {
+			_, potential := mutual.at(s.pos, b.soften, i)
+			if b.pull > 0 {
+				potential /= 2
+			}
+			total.potential += mass * potential
+		}
+		if external != nil {
+			_, potential := external.at(s.pos, b.soften, i)
+			total.potential += mass * potential
+		}
+	}
+	return total
+}
@@ -0,0 +1,342 @@
+package systems
+
+import (
+	"math"
+	"physengine/components"
+	Vec2 "physengine/helpers/vec2"
+
+	"github.com/yohamta/donburi"
+	"github.com/yohamta/donburi/ecs"
+)
+
+const (
+	jointIterations = 10
+	jointBaumgarte  = 0.2 // Fraction of the position error corrected per step
+)
+
+// rigidBody is the velocity state of one body seen by the impulse solvers. Bodies without
+// velocity components or with zero inverse mass act as fixed anchors, and bodies with
+// MotionLimits act as fixed along their locked axes.
+type rigidBody struct {
+	pos     Vec2.Vec2
+	rot     float64
+	vel     *Vec2.Vec2
+	angVel  *float64
+	invMass float64
+	invI    float64
+	limits  components.MotionLimitsData
+}
+
+func newRigidBody(entry *donburi.Entry) rigidBody {
+	tr := components.Transform.Get(entry)
+	body := rigidBody{pos: tr.Pos, rot: tr.Rot, vel: &Vec2.Vec2{}, angVel: new(float64)}
+	if entry.HasComponent(components.Velocity) {
+		body.vel = &components.Velocity.Get(entry).Velocity
+	}
+	if entry.HasComponent(components.AngularVelocity) {
+		body.angVel = &components.AngularVelocity.Get(entry).AngularVelocity
+	}
+	if entry.HasComponent(components.MassComponent) && !components.IsStatic(entry) {
+		mass := components.MassComponent.Get(entry)
+		body.invMass = mass.InverseMass
+		body.invI = components.InverseInertiaOf(entry)
+	}
+	if entry.HasComponent(components.MotionLimits) {
+		body.limits = *components.MotionLimits.Get(entry)
+	}
+	return body
+}
+
+// invMassAlong returns the inverse mass the body shows to impulses along a unit direction
+func (b *rigidBody) invMassAlong(dir Vec2.Vec2) float64 {
+	free := b.limits.Mask(dir)
+	return b.invMass * Vec2.DotProduct(free, free)
+}
+
+// velocityAt returns the velocity of the body point at offset r from its centre
+func (b *rigidBody) velocityAt(r Vec2.Vec2) Vec2.Vec2 {
+	return Vec2.Vec2{X: b.vel.X - *b.angVel*r.Y, Y: b.vel.Y + *b.angVel*r.X}
+}
+
+// applyImpulse applies a linear impulse p and an angular impulse l to the body
+func (b *rigidBody) applyImpulse(p Vec2.Vec2, l float64) {
+	b.vel.AddUpdate(b.limits.Mask(p.Mult(b.invMass)))
+	*b.angVel += l * b.invI
+}
+
+// wheelJointSolver holds the per-step constants of one wheel joint
+type wheelJointSolver struct {
+	joint           *components.WheelJointData
+	a, b            rigidBody
+	ax, ay          Vec2.Vec2 // Suspension axis and its perpendicular, in world space
+	sAx, sBx        float64   // Lever arms of the axis impulse
+	sAy, sBy        float64   // Lever arms of the perpendicular impulse
+	mass            float64   // Effective mass perpendicular to the axis
+	springMass      float64
+	motorMass       float64
+	bias            float64 // Position correction perpendicular to the axis
+	springBias      float64
+	gamma           float64 // Spring softness
+	maxMotorImpulse float64
+}
+
+// jointSolver solves one joint for one step
+type jointSolver interface {
+	solve()
+	// reaction returns the linear impulse the joint has applied so far this step
+	reaction() float64
+}
+
+// UpdateJoints solves all joints with sequential impulses. It runs after the collision
+// step and before the velocities are integrated, so joint forces win over contact pushes.
+// Joints whose reaction force exceeds their BreakForce are removed afterwards.
+func UpdateJoints(e *ecs.ECS) {
+	dt := stepSeconds(e)
+	if dt <= 0 {
+		return
+	}
+
+	var entries []*donburi.Entry
+	var solvers []jointSolver
+	for entry := range components.Joint.Iter(e.World) {
+		joint := components.Joint.Get(entry)
+		if !joint.Valid() {
+			continue
+		}
+		var solver jointSolver
+		switch {
+		case entry.HasComponent(components.WheelJoint):
+			solver = newWheelJointSolver(components.WheelJoint.Get(entry), joint.BodyA, joint.BodyB, dt)
+		case entry.HasComponent(components.DistanceJoint):
+			solver = newDistanceJointSolver(components.DistanceJoint.Get(entry), joint.BodyA, joint.BodyB, dt)
+		case entry.HasComponent(components.RevoluteJoint):
+			solver = newRevoluteJointSolver(components.RevoluteJoint.Get(entry), joint.BodyA, joint.BodyB, dt)
+		default:
+			continue
+		}
+		entries = append(entries, entry)
+		solvers = append(solvers, solver)
+	}
+
+	for i := 0; i < jointIterations; i++ {
+		for _, s := range solvers {
+			s.solve()
+		}
+	}
+
+	for i, entry := range entries {
+		joint := components.Joint.Get(entry)
+		joint.ReactionForce = solvers[i].reaction() / dt
+		if joint.BreakForce > 0 && joint.ReactionForce > joint.BreakForce {
+			e.World.Remove(entry.Entity())
+		}
+	}
+}
+
+func newWheelJointSolver(joint *components.WheelJointData, entryA, entryB *donburi.Entry, dt float64) *wheelJointSolver {
+	s := &wheelJointSolver{joint: joint, a: newRigidBody(entryA), b: newRigidBody(entryB)}
+
+	rA := components.RotatePoint(joint.LocalAnchorA, s.a.rot)
+	rB := components.RotatePoint(joint.LocalAnchorB, s.b.rot)
+	d := s.b.pos.Add(rB).Add(s.a.pos.Add(rA).Mult(-1))
+	iA, iB := s.a.invI, s.b.invI
+
+	// Point-to-line constraint keeps the wheel anchor on the axis
+	s.ax = components.RotatePoint(joint.LocalAxis.Normalized(), s.a.rot)
+	s.ay = Vec2.Vec2{X: -s.ax.Y, Y: s.ax.X}
+	s.sAy = Vec2.CrossProductVecVec(d.Add(rA), s.ay)
+	s.sBy = Vec2.CrossProductVecVec(rB, s.ay)
+	if k := s.a.invMassAlong(s.ay) + s.b.invMassAlong(s.ay) + iA*s.sAy*s.sAy + iB*s.sBy*s.sBy; k > 0 {
+		s.mass = 1 / k
+	}
+	s.bias = jointBaumgarte / dt * Vec2.DotProduct(d, s.ay)
+
+	// Soft spring along the axis
+	s.sAx = Vec2.CrossProductVecVec(d.Add(rA), s.ax)
+	s.sBx = Vec2.CrossProductVecVec(rB, s.ax)
+	if k := s.a.invMassAlong(s.ax) + s.b.invMassAlong(s.ax) + iA*s.sAx*s.sAx + iB*s.sBx*s.sBx; k > 0 && joint.Frequency > 0 {
+		m := 1 / k
+		omega := 2 * math.Pi * joint.Frequency
+		damp := 2 * m * joint.DampingRatio * omega
+		stiffness := m * omega * omega
+		s.gamma = dt * (damp + dt*stiffness)
+		if s.gamma > 0 {
+			s.gamma = 1 / s.gamma
+		}
+		s.springBias = Vec2.DotProduct(d, s.ax) * dt * stiffness * s.gamma
+		s.springMass = 1 / (k + s.gamma)
+	}
+
+	// Rotational motor
+	if k := iA + iB; k > 0 {
+		s.motorMass = 1 / k
+	}
+	s.maxMotorImpulse = joint.MaxMotorTorque * dt
+
+	// Warm start with last step's impulses
+	if !joint.EnableMotor {
+		joint.MotorImpulse = 0
+	}
+	joint.MotorImpulse = math.Max(-s.maxMotorImpulse, math.Min(joint.MotorImpulse, s.maxMotorImpulse))
+	s.applyAxisImpulse(s.ay, s.sAy, s.sBy, joint.Impulse)
+	s.applyAxisImpulse(s.ax, s.sAx, s.sBx, joint.SpringImpulse)
+	*s.a.angVel -= joint.MotorImpulse * s.a.invI
+	*s.b.angVel += joint.MotorImpulse * s.b.invI
+	return s
+}
+
+func (s *wheelJointSolver) solve() {
+	joint := s.joint
+
+	if s.springMass > 0 {
+		cdot := Vec2.DotProduct(s.ax, s.b.vel.Add(s.a.vel.Mult(-1))) + s.sBx**s.b.angVel - s.sAx**s.a.angVel
+		impulse := -s.springMass * (cdot + s.springBias + s.gamma*joint.SpringImpulse)
+		joint.SpringImpulse += impulse
+		s.applyAxisImpulse(s.ax, s.sAx, s.sBx, impulse)
+	}
+
+	if joint.EnableMotor && s.motorMass > 0 {
+		cdot := *s.b.angVel - *s.a.angVel - joint.MotorSpeed
+		old := joint.MotorImpulse
+		joint.MotorImpulse = math.Max(-s.maxMotorImpulse, math.Min(old-s.motorMass*cdot, s.maxMotorImpulse))
+		impulse := joint.MotorImpulse - old
+		*s.a.angVel -= impulse * s.a.invI
+		*s.b.angVel += impulse * s.b.invI
+	}
+
+	if s.mass > 0 {
+		cdot := Vec2.DotProduct(s.ay, s.b.vel.Add(s.a.vel.Mult(-1))) + s.sBy**s.b.angVel - s.sAy**s.a.angVel
+		impulse := -s.mass * (cdot + s.bias)
+		joint.Impulse += impulse
+		s.applyAxisImpulse(s.ay, s.sAy, s.sBy, impulse)
+	}
+}
+
+func (s *wheelJointSolver) reaction() float64 {
+	return math.Hypot(s.joint.Impulse, s.joint.SpringImpulse)
+}
+
+// applyAxisImpulse pushes the two bodies apart along axis, with the given lever arms
+func (s *wheelJointSolver) applyAxisImpulse(axis Vec2.Vec2, sA, sB, impulse float64) {
+	p := axis.Mult(impulse)
+	s.a.applyImpulse(p.Mult(-1), -impulse*sA)
+	s.b.applyImpulse(p, impulse*sB)
+}
+
+// distanceJointSolver holds the per-step constants of one distance joint
+type distanceJointSolver struct {
+	joint  *components.DistanceJointData
+	a, b   rigidBody
+	rA, rB Vec2.Vec2
+	u      Vec2.Vec2 // Unit vector from anchor A to anchor B
+	mass   float64
+	bias   float64
+}
+
+func newDistanceJointSolver(joint *components.DistanceJointData, entryA, entryB *donburi.Entry, dt float64) *distanceJointSolver {
+	s := &distanceJointSolver{joint: joint, a: newRigidBody(entryA), b: newRigidBody(entryB)}
+
+	s.rA = components.RotatePoint(joint.LocalAnchorA, s.a.rot)
+	s.rB = components.RotatePoint(joint.LocalAnchorB, s.b.rot)
+	d := s.b.pos.Add(s.rB).Add(s.a.pos.Add(s.rA).Mult(-1))
+	length := d.Magnitude()
+	if length < 1e-9 {
+		joint.Impulse = 0
+		return s
+	}
+	s.u = d.Mult(1 / length)
+
+	crA := Vec2.CrossProductVecVec(s.rA, s.u)
+	crB := Vec2.CrossProductVecVec(s.rB, s.u)
+	if k := s.a.invMassAlong(s.u) + s.b.invMassAlong(s.u) + s.a.invI*crA*crA + s.b.invI*crB*crB; k > 0 {
+		s.mass = 1 / k
+	}
+	c := length - joint.Length
+	s.bias = jointBaumgarte / dt * c
+	if joint.Rope && c < 0 {
+		// Let a slack rope close the gap in one step but never push
+		s.bias = c / dt
+		joint.Impulse = 0
+	}
+
+	// Warm start with last step's impulse
+	p := s.u.Mult(joint.Impulse)
+	s.a.applyImpulse(p.Mult(-1), -Vec2.CrossProductVecVec(s.rA, p))
+	s.b.applyImpulse(p, Vec2.CrossProductVecVec(s.rB, p))
+	return s
+}
+
+func (s *distanceJointSolver) solve() {
+	if s.mass == 0 {
+		return
+	}
+	vA := s.a.velocityAt(s.rA)
+	vB := s.b.velocityAt(s.rB)
+	cdot := Vec2.DotProduct(s.u, vB.Add(vA.Mult(-1)))
+	impulse := -s.mass * (cdot + s.bias)
+	if s.joint.Rope {
+		// A rope can only pull, so the total impulse stays negative
+		old := s.joint.Impulse
+		s.joint.Impulse = math.Min(0, old+impulse)
+		impulse = s.joint.Impulse - old
+	} else {
+		s.joint.Impulse += impulse
+	}
+	p := s.u.Mult(impulse)
+	s.a.applyImpulse(p.Mult(-1), -Vec2.CrossProductVecVec(s.rA, p))
+	s.b.applyImpulse(p, Vec2.CrossProductVecVec(s.rB, p))
+}
+
+func (s *distanceJointSolver) reaction() float64 {
+	return math.Abs(s.joint.Impulse)
+}
+
+// revoluteJointSolver holds the per-step constants of one revolute joint
+type revoluteJointSolver struct {
+	joint         *components.RevoluteJointData
+	a, b          rigidBody
+	rA, rB        Vec2.Vec2
+	k11, k12, k22 float64 // Effective mass matrix, inverted in solve
+	bias          Vec2.Vec2
+}
+
+func newRevoluteJointSolver(joint *components.RevoluteJointData, entryA, entryB *donburi.Entry, dt float64) *revoluteJointSolver {
+	s := &revoluteJointSolver{joint: joint, a: newRigidBody(entryA), b: newRigidBody(entryB)}
+
+	s.rA = components.RotatePoint(joint.LocalAnchorA, s.a.rot)
+	s.rB = components.RotatePoint(joint.LocalAnchorB, s.b.rot)
+	iA, iB := s.a.invI, s.b.invI
+	s.k11 = s.a.invMassAlong(Vec2.Vec2{X: 1}) + s.b.invMassAlong(Vec2.Vec2{X: 1}) + iA*s.rA.Y*s.rA.Y + iB*s.rB.Y*s.rB.Y
+	s.k12 = -iA*s.rA.X*s.rA.Y - iB*s.rB.X*s.rB.Y
+	s.k22 = s.a.invMassAlong(Vec2.Vec2{Y: 1}) + s.b.invMassAlong(Vec2.Vec2{Y: 1}) + iA*s.rA.X*s.rA.X + iB*s.rB.X*s.rB.X
+
+	c := s.b.pos.Add(s.rB).Add(s.a.pos.Add(s.rA).Mult(-1))
+	s.bias = c.Mult(jointBaumgarte / dt)
+
+	// Warm start with last step's impulse
+	p := joint.Impulse
+	s.a.applyImpulse(p.Mult(-1), -Vec2.CrossProductVecVec(s.rA, p))
+	s.b.applyImpulse(p, Vec2.CrossProductVecVec(s.rB, p))
+	return s
+}
+
+func (s *revoluteJointSolver) solve() {
+	det := s.k11*s.k22 - s.k12*s.k12
+	if det == 0 {
+		return
+	}
+	vA := s.a.velocityAt(s.rA)
+	vB := s.b.velocityAt(s.rB)
+	rhs := vB.Add(vA.Mult(-1)).Add(s.bias).Mult(-1)
+	p := Vec2.Vec2{
+		X: (s.k22*rhs.X - s.k12*rhs.Y) / det,
+		Y: (s.k11*rhs.Y - s.k12*rhs.X) / det,
+	}
+	s.joint.Impulse.AddUpdate(p)
+	s.a.applyImpulse(p.Mult(-1), -Vec2.CrossProductVecVec(s.rA, p))
+	s.b.applyImpulse(p, Vec2.CrossProductVecVec(s.rB, p))
+}
+
+func (s *revoluteJointSolver) reaction() float64 {
+	return s.joint.Impulse.Magnitude()
+}
@@ -0,0 +1,57 @@
+package systems
+
+import (
+	"math"
+	"physengine/components"
+	"physengine/helpers"
+
+	"github.com/yohamta/donburi"
+	"github.com/yohamta/donburi/ecs"
+	"github.com/yohamta/donburi/filter"
+)
+
+// UpdateMotors adds the torque of every enabled motor to its body. It must run before
+// UpdateIntegration, which turns the torque into angular velocity.
+func UpdateMotors(e *ecs.ECS) {
+	dt := stepSeconds(e)
+	if dt <= 0 {
+		return
+	}
+	query := donburi.NewQuery(filter.Contains(components.Motor, components.Torque, components.AngularVelocity, components.MassComponent))
+	for entry := range query.Iter(e.World) {
+		motor := components.Motor.Get(entry)
+		motor.Applied = 0
+		inverseInertia := components.InverseInertiaOf(entry)
+		if !motor.Enabled || inverseInertia <= 0 {
+			continue
+		}
+		inertia := 1 / inverseInertia
+		angVel := components.GetAngularVelocity(entry)
+
+		var torque float64
+		if motor.HoldAngle {
+			angleError := wrapAngle(motor.TargetAngle - components.Transform.Get(entry).Rot)
+			motor.Integral += angleError * dt
+			// Keep the integral term within what the motor can deliver so it cannot wind up
+			if motor.Ki > 0 && motor.MaxTorque > 0 {
+				limit := motor.MaxTorque / (motor.Ki * inertia)
+				motor.Integral = helpers.Clamp(motor.Integral, -limit, limit)
+			}
+			// Damping on the measured speed rather than the error avoids a kick when the target jumps
+			torque = inertia * (motor.Kp*angleError + motor.Ki*motor.Integral - motor.Kd*angVel)
+		} else {
+			// The torque that reaches the target speed in one step
+			torque = (motor.Speed - angVel) * inertia / dt
+		}
+		if motor.MaxTorque > 0 {
+			torque = helpers.Clamp(torque, -motor.MaxTorque, motor.MaxTorque)
+		}
+		motor.Applied = torque
+		components.AddTorque(entry, torque)
+	}
+}
+
+// wrapAngle returns the same angle in the range [-π, π)
+func wrapAngle(angle float64) float64 {
+	return angle - 2*math.Pi*math.Floor((angle+math.Pi)/(2*math.Pi))
+}
@@ -0,0 +1,205 @@
+package systems
+
+import (
+	"math"
+	"math/rand"
+	"physengine/components"
+	Vec2 "physengine/helpers/vec2"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/yohamta/donburi"
+	"github.com/yohamta/donburi/ecs"
+)
+
+// particleRadius is the collision radius of a particle; particles collide as near-points
+const particleRadius = 0.5
+
+// maxBatchVertices is the most vertices one DrawTriangles call accepts
+const maxBatchVertices = 1<<16 - 1
+
+// UpdateParticles spawns particles from emitting emitters, then ages, accelerates and
+// moves every particle, bouncing them off colliders when the emitter asks for it.
+// Rigid bodies do not feel particle collisions.
+func UpdateParticles(e *ecs.ECS) {
+	dt := stepSeconds(e)
+	if dt <= 0 {
+		return
+	}
+	world := components.GetPhysicsWorld(e.World)
+
+	var proxies []components.BroadphaseProxy
+	if resolver_entry, ok := components.CollisionResolverComponent.First(e.World); ok {
+		proxies = broadphaseProxies(components.CollisionResolverComponent.Get(resolver_entry))
+	}
+
+	for entry := range components.ParticleEmitter.Iter(e.World) {
+		emitter := components.ParticleEmitter.Get(entry)
+		if emitter.Emitting && emitter.Rate > 0 && entry.HasComponent(components.Transform) {
+			tr := components.Transform.Get(entry)
+			emitter.SpawnDebt += emitter.Rate * dt
+			count := int(emitter.SpawnDebt)
+			emitter.SpawnDebt -= float64(count)
+			pos := tr.Pos.Add(components.RotatePoint(emitter.Offset, tr.Rot))
+			emitter.Emit(pos, tr.Rot+emitter.Direction, emitter.Spread, 1, count)
+		}
+		if len(emitter.Particles) == 0 {
+			continue
+		}
+
+		var gravity Vec2.Vec2
+		if world != nil {
+			gravity = world.Gravity.Mult(emitter.GravityScale)
+		}
+		drag := math.Exp(-emitter.Drag * dt)
+
+		var nearby []components.BroadphaseProxy
+		if emitter.Collide {
+			min, max := emitter.Bounds()
+			margin := 50.0
+			nearby = nearbyProxies(proxies, min.Add(Vec2.Vec2{X: -margin, Y: -margin}), max.Add(Vec2.Vec2{X: margin, Y: margin}))
+		}
+
+		// Dead particles are swapped with the last one, so order is not preserved
+		for i := 0; i < len(emitter.Particles); {
+			p := &emitter.Particles[i]
+			p.Age += dt
+			if p.Age >= p.Lifetime {
+				last := len(emitter.Particles) - 1
+				emitter.Particles[i] = emitter.Particles[last]
+				emitter.Particles = emitter.Particles[:last]
+				continue
+			}
+			p.Vel.AddUpdate(gravity.Mult(dt))
+			p.Vel.MultUpdate(drag)
+			from := p.Pos
+			p.Pos.AddUpdate(p.Vel.Mult(dt))
+			if len(nearby) > 0 {
+				collideParticle(emitter, p, from, nearby)
+			}
+			i++
+		}
+	}
+}
+
+// collideParticle stops a particle that moved from from into a collider at the surface and
+// reflects its velocity relative to the surface. The move is swept, so fast particles do not
+// tunnel through thin shapes.
+func collideParticle(emitter *components.ParticleEmitterData, p *components.Particle, from Vec2.Vec2, proxies []components.BroadphaseProxy) {
+	delta := p.Pos.Add(from.Mult(-1))
+	point := components.WorldShape{Kind: components.ShapeCircle, Center: from, Vertices: []Vec2.Vec2{from}, Radius: particleRadius}
+	min := Vec2.Vec2{X: math.Min(from.X, p.Pos.X) - particleRadius, Y: math.Min(from.Y, p.Pos.Y) - particleRadius}
+	max := Vec2.Vec2{X: math.Max(from.X, p.Pos.X) + particleRadius, Y: math.Max(from.Y, p.Pos.Y) + particleRadius}
+
+	var first components.ShapeCastHit
+	var hitEntry *donburi.Entry
+	for _, proxy := range proxies {
+		if max.X < proxy.Min.X || min.X > proxy.Max.X || max.Y < proxy.Min.Y || min.Y > proxy.Max.Y {
+			continue
+		}
+		if hit, ok := components.CastShape(point, delta, proxy.Shape); ok && (hitEntry == nil || hit.T < first.T) {
+			first, hitEntry = hit, proxy.Entry
+		}
+	}
+	if hitEntry == nil {
+		return
+	}
+
+	// The hit normal points from the surface towards the particle
+	n := first.Normal
+	p.Pos = from.Add(delta.Mult(first.T)).Add(n.Mult(components.CastTolerance))
+	body := newRigidBody(hitEntry)
+	surface := body.velocityAt(first.Point.Add(body.pos.Mult(-1)))
+	relVel := p.Vel.Add(surface.Mult(-1))
+	vn := Vec2.DotProduct(relVel, n)
+	if vn >= 0 {
+		return
+	}
+	tangent := relVel.Add(n.Mult(-vn))
+	p.Vel = surface.Add(tangent.Mult(1 - emitter.Friction)).Add(n.Mult(-vn * emitter.Restitution))
+}
+
+// UpdateContactSparks sprays sparks from the contacts of the last collision step into every
+// emitter with ContactSparks. Harder hits throw more and faster sparks along the surface.
+// It must run after the collision step.
+func UpdateContactSparks(e *ecs.ECS) {
+	resolver_entry, ok := components.CollisionResolverComponent.First(e.World)
+	if !ok {
+		return
+	}
+	contacts := components.CollisionResolverComponent.Get(resolver_entry).Contacts
+	if len(contacts) == 0 {
+		return
+	}
+
+	for entry := range components.ContactSparks.Iter(e.World) {
+		if !entry.HasComponent(components.ParticleEmitter) {
+			continue
+		}
+		sparks := components.ContactSparks.Get(entry)
+		emitter := components.ParticleEmitter.Get(entry)
+		for _, contact := range contacts {
+			if contact.Impulse < sparks.MinImpulse || contact.Impulse <= 0 {
+				continue
+			}
+			count := int(contact.Impulse * sparks.SparksPerImpulse)
+			if sparks.MaxSparksPerEvent > 0 {
+				count = min(count, sparks.MaxSparksPerEvent)
+			}
+			if count == 0 {
+				continue
+			}
+			speedScale := math.Min(math.Sqrt(contact.Impulse/math.Max(sparks.MinImpulse, 1)), 3)
+			tangent := math.Atan2(contact.Normal.X, -contact.Normal.Y)
+			// Split the sparks between both directions along the surface
+			forward := count / 2
+			if count%2 == 1 && rand.Intn(2) == 0 {
+				forward++
+			}
+			emitter.Emit(contact.Point, tangent, emitter.Spread, speedScale, forward)
+			emitter.Emit(contact.Point, tangent+math.Pi, emitter.Spread, speedScale, count-forward)
+		}
+	}
+}
+
+// drawParticles draws the particles of every emitter seen by the camera as coloured
+// squares, batched into as few DrawTriangles calls as possible
+func drawParticles(e *ecs.ECS, camera *donburi.Entry, screen_camera *ebiten.Image) {
+	camera_tr := components.Transform.Get(camera)
+	camera_comp := components.Camera.Get(camera)
+	view := camera_comp.ScreenRect()
+
+	var vertices []ebiten.Vertex
+	var indices []uint16
+	flush := func() {
+		if len(indices) > 0 {
+			fillTriangles(screen_camera, vertices, indices, ebiten.DrawTrianglesOptions{})
+		}
+		vertices, indices = vertices[:0], indices[:0]
+	}
+
+	for entry := range components.ParticleEmitter.Iter(e.World) {
+		if !camera_comp.SeesEntity(entry) {
+			continue
+		}
+		emitter := components.ParticleEmitter.Get(entry)
+		for _, p := range emitter.Particles {
+			t := p.Age / p.Lifetime
+			half := emitter.SizeAt(t) / 2
+			s := camera_comp.WorldToScreen(camera_tr.Pos, p.Pos)
+			hx, hy := half*camera_comp.Zoom.X, half*camera_comp.Zoom.Y
+			if s.X+hx < float64(view.Min.X) || s.X-hx > float64(view.Max.X) ||
+				s.Y+hy < float64(view.Min.Y) || s.Y-hy > float64(view.Max.Y) {
+				continue
+			}
+			c := emitter.ColorAt(t)
+			if c.A == 0 {
+				continue
+			}
+			if len(vertices)+4 > maxBatchVertices {
+				flush()
+			}
+			vertices, indices = appendFilledQuad(vertices, indices, s, hx, hy, c)
+		}
+	}
+	flush()
+}
@@ -0,0 +1,27 @@
+package systems
+
+import (
+	"physengine/components"
+
+	"github.com/yohamta/donburi/ecs"
+)
+
+// UpdatePlatformPaths steers kinematic platforms back and forth between their two points
+func UpdatePlatformPaths(e *ecs.ECS) {
+	dt := stepSeconds(e)
+	for entry := range components.PlatformPath.Iter(e.World) {
+		path := components.PlatformPath.Get(entry)
+		pos := components.Transform.Get(entry).Pos
+		target := path.B
+		if path.TowardsA {
+			target = path.A
+		}
+		to := target.Add(pos.Mult(-1))
+		if to.Magnitude() <= path.Speed*dt {
+			path.TowardsA = !path.TowardsA
+		}
+		if to.SquareMagnitude() > 0 {
+			components.Velocity.Get(entry).Velocity = to.Normalized().Mult(path.Speed)
+		}
+	}
+}
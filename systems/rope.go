@@ -0,0 +1,62 @@
+package systems
+
+import (
+	"physengine/components"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/yohamta/donburi"
+	"github.com/yohamta/donburi/ecs"
+)
+
+// UpdateRopes keeps a rope's end-to-end limit in step with its links. When the limit breaks
+// first, the rope snaps at its most strained joint; once a rope has torn, the limit is
+// removed so the pieces fall apart.
+func UpdateRopes(e *ecs.ECS) {
+	for entry := range components.Rope.Iter(e.World) {
+		rope := components.Rope.Get(entry)
+		if rope.Limit == nil || (rope.Limit.Valid() && !rope.Torn()) {
+			continue
+		}
+		if rope.Limit.Valid() {
+			e.World.Remove(rope.Limit.Entity())
+		} else if !rope.Torn() {
+			var strongest *donburi.Entry
+			for _, joint := range rope.Joints {
+				if strongest == nil || components.Joint.Get(joint).ReactionForce > components.Joint.Get(strongest).ReactionForce {
+					strongest = joint
+				}
+			}
+			if strongest != nil {
+				e.World.Remove(strongest.Entity())
+			}
+		}
+		rope.Limit = nil
+	}
+}
+
+// drawRopes draws every rope seen by the camera as thick polylines through its joints
+func drawRopes(e *ecs.ECS, camera *donburi.Entry, screen_camera *ebiten.Image) {
+	camera_tr := components.Transform.Get(camera)
+	camera_comp := components.Camera.Get(camera)
+	zoom := (camera_comp.Zoom.X + camera_comp.Zoom.Y) / 2
+
+	for entry := range components.Rope.Iter(e.World) {
+		if !camera_comp.SeesEntity(entry) {
+			continue
+		}
+		rope := components.Rope.Get(entry)
+		width := float32(rope.Thickness * zoom)
+		for _, line := range rope.Polylines() {
+			for i, p := range line {
+				s := camera_comp.WorldToScreen(camera_tr.Pos, p)
+				// Round the corners so bends look smooth
+				vector.DrawFilledCircle(screen_camera, float32(s.X), float32(s.Y), width/2, rope.Color, true)
+				if i > 0 {
+					prev := camera_comp.WorldToScreen(camera_tr.Pos, line[i-1])
+					vector.StrokeLine(screen_camera, float32(prev.X), float32(prev.Y), float32(s.X), float32(s.Y), width, rope.Color, true)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,234 @@
+package systems
+
+import (
+	"math"
+	"physengine/components"
+	Vec2 "physengine/helpers/vec2"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/yohamta/donburi"
+	"github.com/yohamta/donburi/ecs"
+)
+
+// softBodySubsteps splits each step so stiff springs stay stable with explicit integration
+const softBodySubsteps = 8
+
+// UpdateSoftBodies integrates every soft body's points under spring, pressure and gravity
+// forces and resolves point contacts with rigid bodies. It runs after the collision step,
+// whose broadphase proxies it reuses.
+func UpdateSoftBodies(e *ecs.ECS) {
+	dt := stepSeconds(e)
+	if dt <= 0 {
+		return
+	}
+	world := components.GetPhysicsWorld(e.World)
+
+	var proxies []components.BroadphaseProxy
+	if resolver_entry, ok := components.CollisionResolverComponent.First(e.World); ok {
+		proxies = broadphaseProxies(components.CollisionResolverComponent.Get(resolver_entry))
+	}
+
+	h := dt / softBodySubsteps
+	for entry := range components.SoftBody.Iter(e.World) {
+		sb := components.SoftBody.Get(entry)
+		var gravity Vec2.Vec2
+		damping := 0.0
+		if world != nil {
+			gravity = world.Gravity.Mult(components.GetGravityScale(entry))
+			damping = world.DefaultLinearDamping
+		}
+		if entry.HasComponent(components.Damping) {
+			damping = components.Damping.Get(entry).Linear
+		}
+
+		// Rigid bodies barely move within one step, so gather the nearby shapes once
+		min, max := sb.Bounds()
+		margin := sb.PointRadius + 50
+		nearby := nearbyProxies(proxies, min.Add(Vec2.Vec2{X: -margin, Y: -margin}), max.Add(Vec2.Vec2{X: margin, Y: margin}))
+
+		forces := make([]Vec2.Vec2, len(sb.Points))
+		for i := 0; i < softBodySubsteps; i++ {
+			softBodyForces(sb, forces)
+			for j := range sb.Points {
+				p := &sb.Points[j]
+				if p.InverseMass <= 0 {
+					p.Vel = Vec2.Vec2{}
+					continue
+				}
+				p.Vel.AddUpdate(forces[j].Mult(p.InverseMass * h).Add(gravity.Mult(h)))
+				if damping > 0 {
+					p.Vel.MultUpdate(math.Exp(-damping * h))
+				}
+				from := p.Pos
+				p.Pos.AddUpdate(p.Vel.Mult(h))
+				collidePointMass(&p.Pos, &p.Vel, from, p.InverseMass, sb.PointRadius, sb.Friction, nearby)
+			}
+		}
+
+		if entry.HasComponent(components.Transform) {
+			components.Transform.Get(entry).Pos = sb.Center()
+		}
+	}
+}
+
+// nearbyProxies returns the proxies whose bounds overlap the box from min to max
+func nearbyProxies(proxies []components.BroadphaseProxy, min, max Vec2.Vec2) []components.BroadphaseProxy {
+	var nearby []components.BroadphaseProxy
+	for _, proxy := range proxies {
+		if proxy.Min.X > max.X {
+			break // Proxies are sorted by Min.X
+		}
+		if proxy.Max.X < min.X || proxy.Max.Y < min.Y || proxy.Min.Y > max.Y {
+			continue
+		}
+		nearby = append(nearby, proxy)
+	}
+	return nearby
+}
+
+// softBodyForces fills forces with the spring and gas pressure force on each point
+func softBodyForces(sb *components.SoftBodyData, forces []Vec2.Vec2) {
+	for i := range forces {
+		forces[i] = Vec2.Vec2{}
+	}
+
+	for _, spring := range sb.Springs {
+		a, b := &sb.Points[spring.A], &sb.Points[spring.B]
+		d := b.Pos.Add(a.Pos.Mult(-1))
+		length := d.Magnitude()
+		if length < 1e-9 {
+			continue
+		}
+		dir := d.Mult(1 / length)
+		relVel := Vec2.DotProduct(b.Vel.Add(a.Vel.Mult(-1)), dir)
+		f := dir.Mult(spring.Stiffness*(length-spring.RestLength) + spring.Damping*relVel)
+		forces[spring.A].AddUpdate(f)
+		forces[spring.B].AddUpdate(f.Mult(-1))
+	}
+
+	if sb.Pressure <= 0 || len(sb.Hull) < 3 {
+		return
+	}
+	// Gas pressure is inversely proportional to the enclosed area. Squashing a body flat
+	// would need infinite force, so the area is kept above a tenth of the rest area.
+	area := math.Max(sb.HullArea(), sb.RestArea*0.1)
+	pressure := sb.Pressure * sb.RestArea / area
+	for i, index := range sb.Hull {
+		next := sb.Hull[(i+1)%len(sb.Hull)]
+		edge := sb.Points[next].Pos.Add(sb.Points[index].Pos.Mult(-1))
+		// The outward normal of a counter-clockwise edge is on its right; its length is
+		// the edge length, so the force grows with the edge
+		f := Vec2.Vec2{X: edge.Y, Y: -edge.X}.Mult(pressure / 2)
+		forces[index].AddUpdate(f)
+		forces[next].AddUpdate(f)
+	}
+}
+
+// collidePointMass pushes a small circle of the given radius out of the rigid shapes it
+// overlaps, removes its velocity into them with friction, and applies the opposite impulse
+// to dynamic bodies. Soft body points and fluid particles collide this way. from is where
+// the point started the step; its path is swept against static shapes, so neither its own
+// speed nor a push from a body can carry it through thin ground.
+func collidePointMass(pos, vel *Vec2.Vec2, from Vec2.Vec2, invMass, radius, friction float64, proxies []components.BroadphaseProxy) {
+	point := components.WorldShape{Kind: components.ShapeCircle, Center: *pos, Vertices: []Vec2.Vec2{*pos}, Radius: radius}
+	collidePointMassPass(pos, vel, &point, invMass, radius, friction, proxies, false)
+	sweepPointMass(pos, vel, from, &point, proxies)
+	collidePointMassPass(pos, vel, &point, invMass, radius, friction, proxies, true)
+}
+
+// sweepPointMass stops a point that moved from from to pos at the first static shape in the
+// way, keeping only its velocity along the surface
+func sweepPointMass(pos, vel *Vec2.Vec2, from Vec2.Vec2, point *components.WorldShape, proxies []components.BroadphaseProxy) {
+	delta := pos.Add(from.Mult(-1))
+	if delta.SquareMagnitude() < 1e-12 {
+		return
+	}
+	start := point.Translated(from.Add(pos.Mult(-1)))
+	min := Vec2.Vec2{X: math.Min(from.X, pos.X) - point.Radius, Y: math.Min(from.Y, pos.Y) - point.Radius}
+	max := Vec2.Vec2{X: math.Max(from.X, pos.X) + point.Radius, Y: math.Max(from.Y, pos.Y) + point.Radius}
+
+	var first components.ShapeCastHit
+	hit := false
+	for _, proxy := range proxies {
+		if !proxy.Static || max.X < proxy.Min.X || min.X > proxy.Max.X || max.Y < proxy.Min.Y || min.Y > proxy.Max.Y {
+			continue
+		}
+		if h, ok := components.CastShape(start, delta, proxy.Shape); ok && (!hit || h.T < first.T) {
+			first, hit = h, true
+		}
+	}
+	if !hit {
+		return
+	}
+	// The hit normal points out of the static shape
+	*pos = from.Add(delta.Mult(first.T)).Add(first.Normal.Mult(components.CastTolerance))
+	point.Center, point.Vertices[0] = *pos, *pos
+	if vn := Vec2.DotProduct(*vel, first.Normal); vn < 0 {
+		vel.AddUpdate(first.Normal.Mult(-vn))
+	}
+}
+
+func collidePointMassPass(pos, vel *Vec2.Vec2, point *components.WorldShape, invMass, radius, friction float64, proxies []components.BroadphaseProxy, static bool) {
+	for _, proxy := range proxies {
+		if proxy.Static != static {
+			continue
+		}
+		if pos.X+radius < proxy.Min.X || pos.X-radius > proxy.Max.X ||
+			pos.Y+radius < proxy.Min.Y || pos.Y-radius > proxy.Max.Y {
+			continue
+		}
+		contact, ok := components.CollideShapes(*point, proxy.Shape)
+		if !ok {
+			continue
+		}
+		// The normal points from the point mass into the rigid shape
+		n := contact.Normal
+		pos.AddUpdate(n.Mult(-contact.Penetration))
+		point.Center, point.Vertices[0] = *pos, *pos
+
+		body := newRigidBody(proxy.Entry)
+		r := contact.Point.Add(body.pos.Mult(-1))
+		rn := Vec2.CrossProductVecVec(r, n)
+		relVel := vel.Add(body.velocityAt(r).Mult(-1))
+		vn := Vec2.DotProduct(relVel, n)
+		if vn <= 0 {
+			continue
+		}
+		jn := vn / (invMass + body.invMassAlong(n) + rn*rn*body.invI)
+
+		tangent := relVel.Add(n.Mult(-vn))
+		jt := 0.0
+		if vt := tangent.Magnitude(); vt > 1e-9 {
+			tangent = tangent.Mult(1 / vt)
+			rt := Vec2.CrossProductVecVec(r, tangent)
+			jt = math.Min(vt/(invMass+body.invMassAlong(tangent)+rt*rt*body.invI), friction*jn)
+		}
+
+		impulse := n.Mult(jn).Add(tangent.Mult(jt))
+		vel.AddUpdate(impulse.Mult(-invMass))
+		body.applyImpulse(impulse, Vec2.CrossProductVecVec(r, impulse))
+	}
+}
+
+// drawSoftBodies fills and outlines the deformed hull of every soft body seen by the camera
+func drawSoftBodies(e *ecs.ECS, camera *donburi.Entry, screen_camera *ebiten.Image) {
+	camera_tr := components.Transform.Get(camera)
+	camera_comp := components.Camera.Get(camera)
+
+	for entry := range components.SoftBody.Iter(e.World) {
+		if !camera_comp.SeesEntity(entry) {
+			continue
+		}
+		sb := components.SoftBody.Get(entry)
+		if len(sb.Hull) < 3 {
+			continue
+		}
+
+		hull := sb.HullPoints()
+		toScreen := func(p Vec2.Vec2) Vec2.Vec2 {
+			return camera_comp.WorldToScreen(camera_tr.Pos, p)
+		}
+		fillWorldPolygon(screen_camera, toScreen, hull, sb.Fill)
+		strokeWorldPolygon(screen_camera, toScreen, hull, 2, sb.Outline)
+	}
+}
@@ -0,0 +1,45 @@
+package systems
+
+import (
+	"math"
+	Vec2 "physengine/helpers/vec2"
+)
+
+// spatialHash buckets point indices by grid cell, so all points within one cell size of a
+// position can be found by looking at the 3x3 cells around it
+type spatialHash struct {
+	cellSize float64
+	cells    map[[2]int][]int
+}
+
+func newSpatialHash(cellSize float64) *spatialHash {
+	return &spatialHash{cellSize: cellSize, cells: map[[2]int][]int{}}
+}
+
+func (h *spatialHash) cell(p Vec2.Vec2) [2]int {
+	return [2]int{int(math.Floor(p.X / h.cellSize)), int(math.Floor(p.Y / h.cellSize))}
+}
+
+// clear empties every cell but keeps their storage for the next fill
+func (h *spatialHash) clear() {
+	for key, indices := range h.cells {
+		h.cells[key] = indices[:0]
+	}
+}
+
+func (h *spatialHash) insert(index int, p Vec2.Vec2) {
+	key := h.cell(p)
+	h.cells[key] = append(h.cells[key], index)
+}
+
+// near calls fn with every index in the cells around p; callers filter by exact distance
+func (h *spatialHash) near(p Vec2.Vec2, fn func(index int)) {
+	c := h.cell(p)
+	for x := c[0] - 1; x <= c[0]+1; x++ {
+		for y := c[1] - 1; y <= c[1]+1; y++ {
+			for _, index := range h.cells[[2]int{x, y}] {
+				fn(index)
+			}
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package systems
+
+import (
+	"image"
+	"math"
+	"physengine/components"
+	Vec2 "physengine/helpers/vec2"
+	"sort"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/yohamta/donburi"
+	"github.com/yohamta/donburi/ecs"
+	"github.com/yohamta/donburi/filter"
+)
+
+// maxSpritesPerBatch keeps a single DrawTriangles call well below ebiten's vertex limit
+const maxSpritesPerBatch = 4096
+
+// spriteDrawItem is one sprite queued for the current camera
+type spriteDrawItem struct {
+	image      *ebiten.Image
+	imageOrder int
+	source     image.Rectangle
+	layer      int
+	z          float64
+	pos        Vec2.Vec2
+	rot        float64
+	scale      Vec2.Vec2
+	pivot      Vec2.Vec2
+	flipX      bool
+	flipY      bool
+	r, g, b, a float32
+}
+
+// UpdateSpriteAnimation advances frame animations of all playing sprites
+func UpdateSpriteAnimation(e *ecs.ECS) {
+	dt := e.Time.DeltaTime().Seconds()
+	for entry := range components.Sprite.Iter(e.World) {
+		sprite := components.Sprite.Get(entry)
+		if !sprite.Playing || len(sprite.Frames) == 0 || sprite.FrameDuration <= 0 {
+			continue
+		}
+		sprite.FrameTimer += dt
+		for sprite.FrameTimer >= sprite.FrameDuration {
+			sprite.FrameTimer -= sprite.FrameDuration
+			if sprite.CurrentFrame+1 < len(sprite.Frames) {
+				sprite.CurrentFrame++
+			} else if sprite.Loop {
+				sprite.CurrentFrame = 0
+			} else {
+				sprite.Playing = false
+				sprite.FrameTimer = 0
+				break
+			}
+		}
+	}
+}
+
+// drawSprites renders Sprite and Drawable entities seen by the camera, sorted by
+// ZIndex and grouped by source image so each run becomes one DrawTriangles call
+func drawSprites(e *ecs.ECS, camera *donburi.Entry, screen_camera *ebiten.Image) {
+	camera_tr := components.Transform.Get(camera)
+	camera_comp := components.Camera.Get(camera)
+
+	imageOrder := map[*ebiten.Image]int{}
+	orderOf := func(img *ebiten.Image) int {
+		if order, ok := imageOrder[img]; ok {
+			return order
+		}
+		imageOrder[img] = len(imageOrder)
+		return imageOrder[img]
+	}
+
+	var items []spriteDrawItem
+	query := donburi.NewQuery(filter.And(
+		filter.Contains(components.Transform),
+		filter.Or(filter.Contains(components.Sprite), filter.Contains(components.Drawable)),
+	))
+	for entry := range query.Iter(e.World) {
+		if !camera_comp.SeesEntity(entry) {
+			continue
+		}
+		tr := components.Transform.Get(entry)
+		item := spriteDrawItem{pos: tr.Pos, rot: tr.Rot, scale: tr.Scale, r: 1, g: 1, b: 1, a: 1}
+
+		if entry.HasComponent(components.Sprite) {
+			sprite := components.Sprite.Get(entry)
+			if sprite.Image == nil || sprite.Alpha <= 0 {
+				continue
+			}
+			item.image = sprite.Image
+			item.source = sprite.Source()
+			item.pivot = sprite.PivotOffset
+			item.flipX = sprite.FlipX
+			item.flipY = sprite.FlipY
+			// Vertex colours are premultiplied, so alpha scales every channel and the batch is
+			// drawn in premultiplied colour scale mode
+			alpha := float32(sprite.Tint.A) / 255 * float32(sprite.Alpha)
+			item.r = float32(sprite.Tint.R) / 255 * alpha
+			item.g = float32(sprite.Tint.G) / 255 * alpha
+			item.b = float32(sprite.Tint.B) / 255 * alpha
+			item.a = alpha
+		} else {
+			drawable := components.Drawable.Get(entry)
+			if drawable.Sprite == nil {
+				continue
+			}
+			item.image = drawable.Sprite
+			item.source = drawable.Sprite.Bounds()
+		}
+		if entry.HasComponent(components.ZIndex) {
+			zi := components.ZIndex.Get(entry)
+			item.layer = zi.Layer
+			item.z = zi.Z
+		}
+		item.imageOrder = orderOf(item.image)
+		items = append(items, item)
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].layer != items[j].layer {
+			return items[i].layer < items[j].layer
+		}
+		if items[i].z != items[j].z {
+			return items[i].z < items[j].z
+		}
+		return items[i].imageOrder < items[j].imageOrder
+	})
+
+	var vertices []ebiten.Vertex
+	var indices []uint16
+	var batchImage *ebiten.Image
+	flush := func() {
+		if len(indices) > 0 {
+			screen_camera.DrawTriangles(vertices, indices, batchImage, &ebiten.DrawTrianglesOptions{ColorScaleMode: ebiten.ColorScaleModePremultipliedAlpha})
+		}
+		vertices = vertices[:0]
+		indices = indices[:0]
+	}
+
+	for _, item := range items {
+		if item.image != batchImage || len(vertices)/4 >= maxSpritesPerBatch {
+			flush()
+			batchImage = item.image
+		}
+		base := uint16(len(vertices))
+		vertices = appendSpriteQuad(vertices, item, camera_comp, camera_tr.Pos)
+		indices = append(indices, base, base+1, base+2, base+1, base+3, base+2)
+	}
+	flush()
+}
+
+// appendSpriteQuad transforms a sprite's corners the same way as the old GeoM path:
+// center on pivot, scale with zoom, rotate (inverted for the Y-up world) and translate
+func appendSpriteQuad(vertices []ebiten.Vertex, item spriteDrawItem, camera_comp *components.CameraData, camPos Vec2.Vec2) []ebiten.Vertex {
+	w := float64(item.source.Dx())
+	h := float64(item.source.Dy())
+	screen_pos := camera_comp.WorldToScreen(camPos, item.pos)
+
+	sx := item.scale.X * camera_comp.Zoom.X
+	sy := item.scale.Y * camera_comp.Zoom.Y
+	cos := math.Cos(-item.rot)
+	sin := math.Sin(-item.rot)
+
+	u0, u1 := float32(item.source.Min.X), float32(item.source.Max.X)
+	v0, v1 := float32(item.source.Min.Y), float32(item.source.Max.Y)
+	if item.flipX {
+		u0, u1 = u1, u0
+	}
+	if item.flipY {
+		v0, v1 = v1, v0
+	}
+
+	corners := [4]struct {
+		x, y float64
+		u, v float32
+	}{
+		{0, 0, u0, v0},
+		{w, 0, u1, v0},
+		{0, h, u0, v1},
+		{w, h, u1, v1},
+	}
+	for _, c := range corners {
+		lx := (c.x - w/2 - item.pivot.X) * sx
+		ly := (c.y - h/2 - item.pivot.Y) * sy
+		vertices = append(vertices, ebiten.Vertex{
+			DstX:   float32(lx*cos - ly*sin + screen_pos.X),
+			DstY:   float32(lx*sin + ly*cos + screen_pos.Y),
+			SrcX:   c.u,
+			SrcY:   c.v,
+			ColorR: item.r,
+			ColorG: item.g,
+			ColorB: item.b,
+			ColorA: item.a,
+		})
+	}
+	return vertices
+}
@@ -0,0 +1,67 @@
+package systems
+
+import (
+	"fmt"
+	"physengine/components"
+	"physengine/diagnostics"
+
+	"github.com/yohamta/donburi"
+	"github.com/yohamta/donburi/ecs"
+)
+
+// MeasureResolver returns a system that runs the collision system and records the momentum
+// and kinetic energy it changed in the pending telemetry sample. Contacts between dynamic
+// bodies conserve momentum and never add energy, so anything else shows up here.
+func MeasureResolver(collide ecs.System) ecs.System {
+	return func(e *ecs.ECS) {
+		telemetry_entry, ok := components.Telemetry.First(e.World)
+		if !ok {
+			collide(e)
+			return
+		}
+		before := diagnostics.Measure(e.World)
+		collide(e)
+		change := diagnostics.Measure(e.World).Sub(before)
+
+		pending := &components.Telemetry.Get(telemetry_entry).Pending
+		pending.ResolverMomentum = change.Momentum
+		pending.ResolverAngularMomentum = change.AngularMomentum
+		pending.ResolverKinetic = change.Kinetic
+	}
+}
+
+// UpdateTelemetry completes the pending sample with the totals and contacts of the step and
+// adds it to the history. It runs last in each physics step.
+func UpdateTelemetry(e *ecs.ECS) {
+	telemetry_entry, ok := components.Telemetry.First(e.World)
+	if !ok {
+		return
+	}
+	telemetry := components.Telemetry.Get(telemetry_entry)
+
+	sample := telemetry.Pending
+	telemetry.Pending = components.TelemetrySample{}
+	if last, ok := telemetry.Last(); ok {
+		sample.Step, sample.Time = last.Step+1, last.Time
+	}
+	sample.Time += stepSeconds(e)
+
+	totals := diagnostics.Measure(e.World)
+	sample.Momentum, sample.AngularMomentum, sample.Kinetic = totals.Momentum, totals.AngularMomentum, totals.Kinetic
+	if resolver_entry, ok := components.CollisionResolverComponent.First(e.World); ok {
+		contacts := components.CollisionResolverComponent.Get(resolver_entry).Contacts
+		sample.Contacts, sample.MaxPenetration, sample.MeanPenetration = diagnostics.Penetration(contacts)
+	}
+	telemetry.Add(sample)
+}
+
+// exportTelemetry writes the recorded telemetry to CSV and JSON files in the working directory
+func exportTelemetry(w donburi.World) {
+	telemetry_entry, ok := components.Telemetry.First(w)
+	if !ok {
+		return
+	}
+	if err := diagnostics.Export(".", components.Telemetry.Get(telemetry_entry).Samples()); err != nil {
+		fmt.Println(err)
+	}
+}
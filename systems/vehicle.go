@@ -0,0 +1,46 @@
+package systems
+
+import (
+	"math"
+	"physengine/components"
+
+	"github.com/yohamta/donburi/ecs"
+)
+
+// UpdateVehicles sets the wheel joint motors from each vehicle's throttle and brake.
+// It runs before UpdateJoints, which applies the motor torques.
+func UpdateVehicles(e *ecs.ECS) {
+	for entry := range components.Vehicle.Iter(e.World) {
+		vehicle := components.Vehicle.Get(entry)
+		throttle, brake := 0.0, 0.0
+		if vehicle.Input != nil {
+			throttle = math.Max(-1, math.Min(vehicle.Input.Throttle(), 1))
+			brake = math.Max(0, math.Min(vehicle.Input.Brake(), 1))
+		}
+
+		for _, wheel := range vehicle.Wheels {
+			if wheel.Joint == nil || !wheel.Joint.Valid() {
+				continue
+			}
+			joint := components.Joint.Get(wheel.Joint)
+			if !joint.Valid() {
+				continue
+			}
+			motor := components.WheelJoint.Get(wheel.Joint)
+			motor.EnableMotor = true
+			switch {
+			case brake > 0 && wheel.Brakes:
+				motor.MotorSpeed = 0
+				motor.MaxMotorTorque = brake * vehicle.BrakeTorque
+			case throttle != 0 && wheel.Driven:
+				// Rolling to the right is a clockwise, negative spin
+				spin := components.GetAngularVelocity(joint.BodyB) - components.GetAngularVelocity(joint.BodyA)
+				motor.MotorSpeed = -throttle * vehicle.MaxSpeed
+				motor.MaxMotorTorque = math.Abs(throttle) * vehicle.TorqueAt(spin)
+			default:
+				motor.MotorSpeed = 0
+				motor.MaxMotorTorque = vehicle.RollingResistance
+			}
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package systems
+
+import "github.com/hajimehoshi/ebiten/v2"
+
+// KeyboardVehicleInput drives a vehicle with D or Right to accelerate, A or Left to
+// reverse and Space or S to brake
+type KeyboardVehicleInput struct{}
+
+func (KeyboardVehicleInput) Throttle() float64 {
+	throttle := 0.0
+	if ebiten.IsKeyPressed(ebiten.KeyA) || ebiten.IsKeyPressed(ebiten.KeyArrowLeft) {
+		throttle--
+	}
+	if ebiten.IsKeyPressed(ebiten.KeyD) || ebiten.IsKeyPressed(ebiten.KeyArrowRight) {
+		throttle++
+	}
+	return throttle
+}
+
+func (KeyboardVehicleInput) Brake() float64 {
+	if ebiten.IsKeyPressed(ebiten.KeySpace) || ebiten.IsKeyPressed(ebiten.KeyS) || ebiten.IsKeyPressed(ebiten.KeyArrowDown) {
+		return 1
+	}
+	return 0
+}